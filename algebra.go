@@ -0,0 +1,123 @@
+package runes
+
+import "unicode/utf8"
+
+// UnionOf returns a new set with the runes that are in any of the given sets.
+// The result is materialized and optimized with [Compile], so it does not
+// reference the given sets. It is not named Union to avoid clashing with the
+// [Union] type.
+func UnionOf(sets ...MinMaxSet) MinMaxSet {
+	return fold(sets, or)
+}
+
+// Intersection returns a new set with the runes that are in all the given
+// sets. The result is materialized and optimized with [Compile].
+func Intersection(sets ...MinMaxSet) MinMaxSet {
+	return fold(sets, and)
+}
+
+// Difference returns a new set with the runes of `a` that are not in `b`. The
+// result is materialized and optimized with [Compile].
+func Difference(a, b MinMaxSet) MinMaxSet {
+	return fold([]MinMaxSet{a, b}, andNot)
+}
+
+// SymmetricDifference returns a new set with the runes that are in either `a`
+// or `b`, but not in both. The result is materialized and optimized with
+// [Compile].
+func SymmetricDifference(a, b MinMaxSet) MinMaxSet {
+	return fold([]MinMaxSet{a, b}, xor)
+}
+
+// Complement returns a new set with the runes in [0, utf8.MaxRune] that are
+// not in `s`. The result is materialized and optimized with [Compile].
+func Complement(s MinMaxSet) MinMaxSet {
+	return Difference(Interval[rune]{0, utf8.MaxRune}, s)
+}
+
+// Operations for fold and combineBounds.
+func or(inA, inB bool) bool     { return inA || inB }
+func and(inA, inB bool) bool    { return inA && inB }
+func andNot(inA, inB bool) bool { return inA && !inB }
+func xor(inA, inB bool) bool    { return inA != inB }
+
+// fold combines the bounds of the given sets from left to right with `op`,
+// and compiles the result.
+func fold(sets []MinMaxSet, op func(inA, inB bool) bool) MinMaxSet {
+	var b []rune
+	for i, s := range sets {
+		if i == 0 {
+			b = setBounds(s)
+			continue
+		}
+		b = combineBounds(b, setBounds(s), op)
+	}
+	return compileBounds(b)
+}
+
+// setBounds returns the bounds of the ranges of `s`, which are the runes where
+// the membership changes: the even indexes hold the first rune of each range,
+// and the odd indexes the rune after the last one. Runes outside of [0,
+// utf8.MaxRune] are dropped, like [Compile] does.
+func setBounds(s MinMaxSet) []rune {
+	var b []rune
+	for lo, hi := range validRanges(s) {
+		b = append(b, lo, hi+1)
+	}
+	return b
+}
+
+// combineBounds returns the bounds of the set that contains the runes for which
+// `op` returns true, given whether they are in the sets with bounds `a` and
+// `b`.
+func combineBounds(a, b []rune, op func(inA, inB bool) bool) []rune {
+	var res []rune
+	var i, j int
+	var in bool
+	for i < len(a) || j < len(b) {
+		var p rune
+		switch {
+		case i == len(a):
+			p = b[j]
+		case j == len(b):
+			p = a[i]
+		default:
+			p = min(a[i], b[j])
+		}
+		if i < len(a) && a[i] == p {
+			i++
+		}
+		if j < len(b) && b[j] == p {
+			j++
+		}
+		if v := op(i%2 == 1, j%2 == 1); v != in {
+			res = append(res, p)
+			in = v
+		}
+	}
+	return res
+}
+
+// compileBounds compiles the set with the given bounds.
+func compileBounds(b []rune) MinMaxSet {
+	return newCompileConfig(nil).compile(boundsAtoms(b))
+}
+
+// boundsAtoms returns the atoms that splitAtoms would return for the runes
+// within the given bounds, without enumerating them. Ranges of more than one
+// rune are never split by splitAtoms, so only the runs of single runes
+// between them need to be split.
+func boundsAtoms(b []rune) []atom {
+	var atoms []atom
+	var single []rune
+	for i := 0; i+1 < len(b); i += 2 {
+		lo, hi := b[i], b[i+1]-1
+		if lo == hi {
+			single = append(single, lo)
+			continue
+		}
+		atoms = append(append(atoms, splitAtoms(single)...), atom{lo, hi, 1, int(hi-lo) + 1})
+		single = single[:0]
+	}
+	return append(atoms, splitAtoms(single)...)
+}
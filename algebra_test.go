@@ -0,0 +1,119 @@
+package runes
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestAlgebra(t *testing.T) {
+	t.Parallel()
+	letter := FromRangeTable(unicode.Letter)
+	latin := FromRangeTable(unicode.Latin)
+	punct := FromRangeTable(unicode.Punct)
+	symbol := FromRangeTable(unicode.Symbol)
+	isLetter := func(r rune) bool { return unicode.Is(unicode.Letter, r) }
+	isLatin := func(r rune) bool { return unicode.Is(unicode.Latin, r) }
+	isPunct := func(r rune) bool { return unicode.Is(unicode.Punct, r) }
+	isSymbol := func(r rune) bool { return unicode.Is(unicode.Symbol, r) }
+	odd := minMaxFunc{
+		ContainsFunc: func(r rune) bool { return r%2 == 1 },
+		min:          1,
+		max:          99,
+	}
+
+	testCases := []struct {
+		set      Set
+		expected func(rune) bool
+	}{
+		{UnionOf(), func(rune) bool { return false }},
+		{Intersection(), func(rune) bool { return false }},
+		{UnionOf(punct), isPunct},
+		{UnionOf(punct, symbol), func(r rune) bool { return isPunct(r) || isSymbol(r) }},
+		{UnionOf(punct, symbol, latin), func(r rune) bool { return isPunct(r) || isSymbol(r) || isLatin(r) }},
+		{Intersection(letter, latin), func(r rune) bool { return isLetter(r) && isLatin(r) }},
+		{Intersection(letter, punct), func(rune) bool { return false }},
+		{Difference(letter, latin), func(r rune) bool { return isLetter(r) && !isLatin(r) }},
+		{Difference(latin, letter), func(r rune) bool { return isLatin(r) && !isLetter(r) }},
+		{SymmetricDifference(letter, latin), func(r rune) bool { return isLetter(r) != isLatin(r) }},
+		{Complement(punct), func(r rune) bool { return r >= 0 && r <= utf8.MaxRune && !isPunct(r) }},
+		{Complement(Complement(punct)), isPunct},
+		{Complement(LinearSlice[uint8](nil)), func(r rune) bool { return r >= 0 && r <= utf8.MaxRune }},
+		{Complement(Interval[rune]{0, utf8.MaxRune}), func(rune) bool { return false }},
+		{Difference(Interval[uint8]{0, 200}, odd), func(r rune) bool { return r >= 0 && r <= 200 && (r%2 == 0 || r > 99) }},
+		// runes outside of [0, utf8.MaxRune] are dropped
+		{Difference(LinearSlice[rune]{-5, 3, 7}, LinearSlice[rune]{7}), func(r rune) bool { return r == 3 }},
+		{UnionOf(LinearSlice[rune]{3, 0x200000}), func(r rune) bool { return r == 3 }},
+		{UnionOf(Interval[rune]{-10, 5}), func(r rune) bool { return r >= 0 && r <= 5 }},
+		{Complement(Interval[rune]{0x10fff0, math.MaxInt32}), func(r rune) bool { return r >= 0 && r < 0x10fff0 }},
+		{Intersection(Interval[rune]{math.MinInt32, math.MaxInt32}, Interval[uint8]{1, 2}), func(r rune) bool { return r == 1 || r == 2 }},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			testPredicateEquivalence(t, tc.set, tc.expected)
+		})
+	}
+}
+
+func TestCombineBounds(t *testing.T) {
+	t.Parallel()
+	or := func(inA, inB bool) bool { return inA || inB }
+	and := func(inA, inB bool) bool { return inA && inB }
+	testCases := []struct {
+		a, b     []rune
+		op       func(inA, inB bool) bool
+		expected []rune
+	}{
+		{nil, nil, or, nil},
+		{[]rune{1, 3}, nil, or, []rune{1, 3}},
+		{nil, []rune{1, 3}, and, nil},
+		{[]rune{1, 3}, []rune{3, 5}, or, []rune{1, 5}},
+		{[]rune{1, 3}, []rune{3, 5}, and, nil},
+		{[]rune{1, 4}, []rune{3, 5}, and, []rune{3, 4}},
+		{[]rune{1, 4, 6, 9}, []rune{0, 2, 3, 7}, and, []rune{1, 2, 3, 4, 6, 7}},
+	}
+
+	for i, tc := range testCases {
+		got := combineBounds(tc.a, tc.b, tc.op)
+		util.Equal(t, fmt.Sprint(tc.expected), fmt.Sprint(got), "index=%v", i)
+	}
+}
+
+func TestBoundsAtoms(t *testing.T) {
+	t.Parallel()
+	testCases := [][]rune{
+		nil,
+		{1, 2},
+		{1, 2, 3, 4, 5, 6},
+		{1, 2, 3, 4, 5, 8},
+		{1, 2, 5, 8, 9, 10, 20, 21},
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, utf8.MaxRune, utf8.MaxRune + 1},
+		{0, utf8.MaxRune + 1},
+	}
+
+	for i, b := range testCases {
+		var rs []rune
+		for j := 0; j < len(b); j += 2 {
+			for r := b[j]; r < b[j+1]; r++ {
+				rs = append(rs, r)
+			}
+		}
+		got := boundsAtoms(b)
+		util.Equal(t, fmt.Sprint(splitAtoms(rs)), fmt.Sprint(got), "index=%v", i)
+	}
+}
+
+// testPredicateEquivalence checks that `s` contains exactly the runes for which
+// `f` returns true.
+func testPredicateEquivalence(t *testing.T, s Set, f func(rune) bool) {
+	t.Helper()
+	for r := rune(-1); r <= utf8.MaxRune+1; r++ {
+		util.MustEqual(t, f(r), s.Contains(r), "rune=0x%x", r)
+	}
+}
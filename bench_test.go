@@ -2,6 +2,7 @@ package runes
 
 import (
 	"fmt"
+	"maps"
 	"slices"
 	"testing"
 	"unicode"
@@ -73,3 +74,116 @@ func isSpaceCustom() Bitmap {
 		12288,
 	})
 }
+
+// benchTables are the names of the tables of util.Tables used in benchmarks.
+var benchTables = []string{"White_Space", "Greek", "Nd", "L", "Lu", "Po", "Han"}
+
+// benchTypes are the implementations compared in benchmarks, built from a
+// table and its runes.
+var benchTypes = []struct {
+	name string
+	new  func(rt *unicode.RangeTable, rs []rune) Set
+}{
+	{"stdlib", func(rt *unicode.RangeTable, _ []rune) Set {
+		return util.ContainsFunc(func(r rune) bool { return unicode.Is(rt, r) })
+	}},
+	{"Compile", func(_ *unicode.RangeTable, rs []rune) Set { return Compile(rs) }},
+	{"FromRangeTable", func(rt *unicode.RangeTable, _ []rune) Set { return FromRangeTable(rt) }},
+	{"LinearSlice", func(_ *unicode.RangeTable, rs []rune) Set { return newNarrowSlice(true, rs) }},
+	{"BinarySlice", func(_ *unicode.RangeTable, rs []rune) Set { return newNarrowSlice(false, rs) }},
+	{"RangeSlice", func(_ *unicode.RangeTable, rs []rune) Set {
+		return narrowest(rs[len(rs)-1], toSet(NewRangeSlice[uint8]), toSet(NewRangeSlice[uint16]), toSet(NewRangeSlice[rune]))(rs)
+	}},
+	{"StridedRanges", func(_ *unicode.RangeTable, rs []rune) Set {
+		return narrowest(rs[len(rs)-1], toSet(NewStridedRanges[uint8]), toSet(NewStridedRanges[uint16]), toSet(NewStridedRanges[rune]))(rs)
+	}},
+	{"Bitmap", func(_ *unicode.RangeTable, rs []rune) Set { return NewBitmap(rs) }},
+	{"SparseBitmap", func(_ *unicode.RangeTable, rs []rune) Set { return NewSparseBitmap(rs) }},
+	{"Roaring", func(_ *unicode.RangeTable, rs []rune) Set { return NewRoaring(rs) }},
+	{"MixedUnion", func(_ *unicode.RangeTable, rs []rune) Set { return NewMixedUnion(rs) }},
+	{"TwoLevel", func(_ *unicode.RangeTable, rs []rune) Set { return NewTwoLevel(rs) }},
+	{"Union", func(_ *unicode.RangeTable, rs []rune) Set { return Union[Interval[rune]](rangeIntervals(rs)) }},
+	{"SortedUnion", func(_ *unicode.RangeTable, rs []rune) Set { return MustSortedUnion(rangeIntervals(rs)...) }},
+}
+
+func toSet[S Set](f func([]rune) S) func([]rune) Set {
+	return func(rs []rune) Set { return f(rs) }
+}
+
+// rangeIntervals returns an Interval for each range of `rs`.
+func rangeIntervals(rs []rune) []Interval[rune] {
+	var res []Interval[rune]
+	for lo, hi := range runesRanges(slices.Values(rs)) {
+		res = append(res, Interval[rune]{lo, hi})
+	}
+	return res
+}
+
+// benchRunes returns the runes looked up in benchmarks: every rune of the
+// table and the ones next to them, plus runes evenly spread over the first
+// three planes.
+func benchRunes(rs []rune) []rune {
+	var res []rune
+	for _, r := range rs {
+		res = append(res, r-1, r, r+1)
+	}
+	for r := rune(0); r < 0x30000; r += 97 {
+		res = append(res, r)
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+func BenchmarkContains(b *testing.B) {
+	for _, name := range benchTables {
+		rt := util.Tables[name]
+		rs := slices.Collect(util.RangeTableIter(rt))
+		testRunes := benchRunes(rs)
+		b.Run("table="+name, func(b *testing.B) {
+			for _, bt := range benchTypes {
+				s := bt.new(rt, rs)
+				if bt.name == "stdlib" {
+					b.Logf("%s: estimated size in bytes: %s", bt.name,
+						util.FormatSizeEstimation(util.SizeofUnicodeRangeTable(rt)))
+				} else {
+					b.Logf("%s: estimated size in bytes: %s", bt.name,
+						util.FormatSizeEstimation(util.Sizeof(s)))
+				}
+				b.Run("implem="+bt.name, func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						s.Contains(testRunes[i%len(testRunes)])
+					}
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkTwoLevel compares TwoLevel with the standard library for every table
+// of util.Tables.
+func BenchmarkTwoLevel(b *testing.B) {
+	for _, name := range slices.Sorted(maps.Keys(util.Tables)) {
+		rt := util.Tables[name]
+		rs := slices.Collect(util.RangeTableIter(rt))
+		if len(rs) == 0 {
+			continue
+		}
+		testRunes := benchRunes(rs)
+		s := NewTwoLevel(rs)
+		b.Logf("%s: estimated size in bytes: stdlib %s, TwoLevel %s", name,
+			util.FormatSizeEstimation(util.SizeofUnicodeRangeTable(rt)),
+			util.FormatSizeEstimation(util.Sizeof(s)))
+		b.Run("table="+name, func(b *testing.B) {
+			b.Run("implem=stdlib", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					unicode.Is(rt, testRunes[i%len(testRunes)])
+				}
+			})
+			b.Run("implem=TwoLevel", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					s.Contains(testRunes[i%len(testRunes)])
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,1161 @@
+package runes
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// The binary encoding of a set is a version byte followed by the encoding of
+// a value. Values start with a tag byte holding the kind of the set in its
+// high 6 bits and the width of its RuneT in the low 2 bits, followed by a
+// payload that depends on the kind:
+//
+//	Interval       From and To
+//	Uniform        Lo, Hi and Stride
+//	LinearSlice    uvarint length, then the elements
+//	BinarySlice    uvarint length, then the elements
+//	Bitmap         uvarint length, then the bytes of the Bitmap
+//	RangeSlice     uvarint length, then From and To of each element
+//	StridedRanges  uvarint length, then Lo, Hi and Stride of each element
+//	TwoLevel       uvarint First, uvarint length and elements of Index, then
+//	               uvarint length and elements of Leaves
+//	SparseBitmap   uvarint length, then the block and word of each element
+//	Roaring        uvarint length, then for each container its key, a byte with
+//	               its kind, uvarint length and the elements of its Data
+//	MixedUnion     uvarint length, then the kind, bounds and Arg of each
+//	               segment, then uvarint length and elements of Runes and of
+//	               Words
+//	Union          member tag, uvarint length, then the members
+//	SortedUnion    like Union
+//
+// Runes are encoded in little-endian using the width of their RuneT, and so
+// are the uint16, uint32 and uint64 elements of TwoLevel, SparseBitmap, Roaring
+// and MixedUnion, where kinds are a single byte. If all the members of a Union
+// have the same tag, then it is written as the member tag and the members are
+// encoded without their own tag. Otherwise, the member tag is zero and each
+// member is a complete value.
+
+// binaryVersion is the version of the binary encoding.
+const binaryVersion = 1
+
+// maxBinaryDepth is the maximum nesting of Unions accepted by Decode.
+const maxBinaryDepth = 32
+
+const (
+	kindUnion byte = 1 + iota
+	kindLinearSlice
+	kindBinarySlice
+	kindInterval
+	kindUniform
+	kindBitmap
+	kindRangeSlice
+	kindStridedRanges
+	kindTwoLevel
+	kindSparseBitmap
+	kindRoaring
+	kindSortedUnion
+	kindMixedUnion
+)
+
+// RuneT width codes in the low 2 bits of a tag.
+const (
+	widthUint8 byte = iota
+	widthUint16
+	widthUint32
+	widthRune
+)
+
+var (
+	// ErrInvalidEncoding is returned when decoding malformed data.
+	ErrInvalidEncoding = errors.New("runes: invalid encoding")
+	// ErrUnsupportedSet is returned when encoding a set that has no binary
+	// encoding.
+	ErrUnsupportedSet = errors.New("runes: unsupported set")
+)
+
+// binarySet is implemented by the sets with a binary encoding.
+type binarySet interface {
+	MinMaxSet
+	binaryTag() byte
+	appendPayload([]byte) ([]byte, error)
+}
+
+// Decode decodes a set encoded with MarshalBinary, validating that it holds
+// the invariants of its type. Unions whose members have the same tag are
+// decoded with that concrete member type.
+func Decode(data []byte) (MinMaxSet, error) {
+	return decode(decoder{b: data})
+}
+
+func decode(d decoder) (MinMaxSet, error) {
+	if err := d.version(); err != nil {
+		return nil, err
+	}
+	s, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.b) > 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidEncoding, len(d.b))
+	}
+	return s, nil
+}
+
+func marshalBinary(s binarySet) ([]byte, error) {
+	return appendBinary(nil, s)
+}
+
+func appendBinary(b []byte, s binarySet) ([]byte, error) {
+	return appendValue(append(b, binaryVersion), s)
+}
+
+// unmarshalBinary decodes `data` into `x`, which must have exactly the type of
+// the encoded set.
+func unmarshalBinary[T MinMaxSet](x *T, data []byte) error {
+	s, err := Decode(data)
+	if err != nil {
+		return err
+	}
+	v, ok := s.(T)
+	if !ok {
+		return fmt.Errorf("%w: cannot decode %T into %T", ErrInvalidEncoding, s, *x)
+	}
+	*x = v
+	return nil
+}
+
+func appendValue(b []byte, s MinMaxSet) ([]byte, error) {
+	bs, ok := s.(binarySet)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedSet, s)
+	}
+	return bs.appendPayload(append(b, bs.binaryTag()))
+}
+
+func (x Union[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x Union[T]) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *Union[T]) UnmarshalBinary(data []byte) error {
+	s, err := Decode(data)
+	if err != nil {
+		return err
+	}
+	// any Union can be decoded into a Union of a compatible member type
+	var members []MinMaxSet
+	switch u := s.(type) {
+	case Union[T]:
+		*x = u
+		return nil
+	case interface{ members() []MinMaxSet }:
+		members = u.members()
+	default:
+		return fmt.Errorf("%w: cannot decode %T into %T", ErrInvalidEncoding, s, *x)
+	}
+	res := make(Union[T], len(members))
+	for i, m := range members {
+		v, ok := m.(T)
+		if !ok {
+			return fmt.Errorf("%w: cannot decode member %T into %T", ErrInvalidEncoding, m, v)
+		}
+		res[i] = v
+	}
+	*x = res
+	return nil
+}
+
+func (x Union[T]) members() []MinMaxSet {
+	res := make([]MinMaxSet, len(x))
+	for i := range x {
+		res[i] = x[i]
+	}
+	return res
+}
+
+func (x Union[T]) binaryTag() byte {
+	return kindUnion << 2
+}
+
+func (x Union[T]) appendPayload(b []byte) ([]byte, error) {
+	return appendMembers(b, x)
+}
+
+func (x SortedUnion[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x SortedUnion[T]) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+// UnmarshalBinary decodes a Union or a SortedUnion, whose members must be
+// sorted and must not overlap.
+func (x *SortedUnion[T]) UnmarshalBinary(data []byte) error {
+	var u Union[T]
+	if err := u.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	res, err := NewSortedUnion(u...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+	*x = res
+	return nil
+}
+
+func (x SortedUnion[T]) members() []MinMaxSet {
+	return Union[T](x.elems).members()
+}
+
+func (x SortedUnion[T]) binaryTag() byte {
+	return kindSortedUnion << 2
+}
+
+func (x SortedUnion[T]) appendPayload(b []byte) ([]byte, error) {
+	return appendMembers(b, x.elems)
+}
+
+// appendMembers appends the payload of a Union or SortedUnion with the given
+// members.
+func appendMembers[T MinMaxSet](b []byte, x []T) ([]byte, error) {
+	var memberTag byte
+	for i := range x {
+		bs, ok := any(x[i]).(binarySet)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", ErrUnsupportedSet, x[i])
+		}
+		if i == 0 {
+			memberTag = bs.binaryTag()
+		} else if memberTag != bs.binaryTag() {
+			memberTag = 0
+		}
+	}
+	b = binary.AppendUvarint(append(b, memberTag), uint64(len(x)))
+	for i := range x {
+		var err error
+		if memberTag != 0 {
+			b, err = any(x[i]).(binarySet).appendPayload(b)
+		} else {
+			b, err = appendValue(b, x[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (x LinearSlice[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x LinearSlice[T]) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *LinearSlice[T]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x LinearSlice[T]) binaryTag() byte {
+	return kindLinearSlice<<2 | widthCode[T]()
+}
+
+func (x LinearSlice[T]) appendPayload(b []byte) ([]byte, error) {
+	return appendRuneTs(b, x), nil
+}
+
+func (x BinarySlice[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x BinarySlice[T]) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *BinarySlice[T]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x BinarySlice[T]) binaryTag() byte {
+	return kindBinarySlice<<2 | widthCode[T]()
+}
+
+func (x BinarySlice[T]) appendPayload(b []byte) ([]byte, error) {
+	return appendRuneTs(b, x), nil
+}
+
+func (x RangeSlice[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x RangeSlice[T]) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *RangeSlice[T]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x RangeSlice[T]) binaryTag() byte {
+	return kindRangeSlice<<2 | widthCode[T]()
+}
+
+func (x RangeSlice[T]) appendPayload(b []byte) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(len(x)))
+	for _, v := range x {
+		b = appendRuneT(appendRuneT(b, v.From), v.To)
+	}
+	return b, nil
+}
+
+func (x StridedRanges[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x StridedRanges[T]) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *StridedRanges[T]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x StridedRanges[T]) binaryTag() byte {
+	return kindStridedRanges<<2 | widthCode[T]()
+}
+
+func (x StridedRanges[T]) appendPayload(b []byte) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(len(x)))
+	for _, v := range x {
+		b = appendRuneT(appendRuneT(appendRuneT(b, v.Lo), v.last()), v.Stride)
+	}
+	return b, nil
+}
+
+func (x Interval[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x Interval[T]) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *Interval[T]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x Interval[T]) binaryTag() byte {
+	return kindInterval<<2 | widthCode[T]()
+}
+
+func (x Interval[T]) appendPayload(b []byte) ([]byte, error) {
+	return appendRuneT(appendRuneT(b, x.From), x.To), nil
+}
+
+func (x Uniform[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x Uniform[T]) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *Uniform[T]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x Uniform[T]) binaryTag() byte {
+	return kindUniform<<2 | widthCode[T]()
+}
+
+func (x Uniform[T]) appendPayload(b []byte) ([]byte, error) {
+	return appendRuneT(appendRuneT(appendRuneT(b, x.Lo), x.last()), x.Stride), nil
+}
+
+// last returns the biggest rune of a valid Uniform, which is the one on its
+// stride at or before Hi. Decoding requires Hi to be on the stride.
+func (x Uniform[T]) last() T {
+	if x.Stride == 0 || x.Lo > x.Hi {
+		return x.Hi
+	}
+	return x.Lo + (x.Hi-x.Lo)/x.Stride*x.Stride
+}
+
+func (x Bitmap) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x Bitmap) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *Bitmap) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x Bitmap) binaryTag() byte {
+	return kindBitmap << 2
+}
+
+func (x Bitmap) appendPayload(b []byte) ([]byte, error) {
+	return append(binary.AppendUvarint(b, uint64(len(x))), x...), nil
+}
+
+func (x TwoLevel) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x TwoLevel) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *TwoLevel) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x TwoLevel) binaryTag() byte {
+	return kindTwoLevel << 2
+}
+
+func (x TwoLevel) appendPayload(b []byte) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(x.First))
+	b = binary.AppendUvarint(b, uint64(len(x.Index)))
+	for _, v := range x.Index {
+		b = binary.LittleEndian.AppendUint16(b, v)
+	}
+	b = binary.AppendUvarint(b, uint64(len(x.Leaves)))
+	for _, v := range x.Leaves {
+		b = binary.LittleEndian.AppendUint64(b, v)
+	}
+	return b, nil
+}
+
+func (x SparseBitmap) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x SparseBitmap) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *SparseBitmap) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x SparseBitmap) binaryTag() byte {
+	return kindSparseBitmap << 2
+}
+
+func (x SparseBitmap) appendPayload(b []byte) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(len(x.Blocks)))
+	for i, block := range x.Blocks {
+		b = binary.LittleEndian.AppendUint16(b, block)
+		b = binary.LittleEndian.AppendUint64(b, x.word(i))
+	}
+	return b, nil
+}
+
+func (x Roaring) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x Roaring) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *Roaring) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x Roaring) binaryTag() byte {
+	return kindRoaring << 2
+}
+
+func (x Roaring) appendPayload(b []byte) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(len(x.Keys)))
+	for i, key := range x.Keys {
+		c := x.container(i)
+		b = append(binary.LittleEndian.AppendUint16(b, key), byte(c.Kind))
+		b = binary.AppendUvarint(b, uint64(len(c.Data)))
+		for _, v := range c.Data {
+			b = binary.LittleEndian.AppendUint16(b, v)
+		}
+	}
+	return b, nil
+}
+
+func (x MixedUnion) MarshalBinary() ([]byte, error) {
+	return marshalBinary(x)
+}
+
+func (x MixedUnion) AppendBinary(b []byte) ([]byte, error) {
+	return appendBinary(b, x)
+}
+
+func (x *MixedUnion) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(x, data)
+}
+
+func (x MixedUnion) binaryTag() byte {
+	return kindMixedUnion << 2
+}
+
+func (x MixedUnion) appendPayload(b []byte) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(x.segments()))
+	for i, kind := range x.Kinds[:x.segments()] {
+		b = append(b, byte(kind))
+		b = binary.LittleEndian.AppendUint32(b, x.Bounds[2*i])
+		b = binary.LittleEndian.AppendUint32(b, x.Bounds[2*i+1])
+		b = binary.LittleEndian.AppendUint32(b, x.arg(i))
+	}
+	b = binary.AppendUvarint(b, uint64(len(x.Runes)))
+	for _, v := range x.Runes {
+		b = binary.LittleEndian.AppendUint32(b, v)
+	}
+	b = binary.AppendUvarint(b, uint64(len(x.Words)))
+	for _, v := range x.Words {
+		b = binary.LittleEndian.AppendUint64(b, v)
+	}
+	return b, nil
+}
+
+// widthCode returns the code of the width of T.
+func widthCode[T RuneT]() byte {
+	switch any(*new(T)).(type) {
+	case uint8:
+		return widthUint8
+	case uint16:
+		return widthUint16
+	case uint32:
+		return widthUint32
+	default:
+		return widthRune
+	}
+}
+
+// widthBytes returns the number of bytes of a RuneT with the given code.
+func widthBytes(code byte) int {
+	return [...]int{1, 2, 4, 4}[code&3]
+}
+
+func appendRuneT[T RuneT](b []byte, v T) []byte {
+	switch widthCode[T]() {
+	case widthUint8:
+		return append(b, byte(v))
+	case widthUint16:
+		return binary.LittleEndian.AppendUint16(b, uint16(v))
+	default:
+		return binary.LittleEndian.AppendUint32(b, uint32(v))
+	}
+}
+
+func appendRuneTs[T RuneT](b []byte, vs []T) []byte {
+	b = binary.AppendUvarint(b, uint64(len(vs)))
+	for _, v := range vs {
+		b = appendRuneT(b, v)
+	}
+	return b
+}
+
+// decoder decodes the binary encoding of sets.
+type decoder struct {
+	b     []byte
+	depth int
+	// noCopy makes Bitmaps and slices of uint8 reference `b` instead of
+	// copying it
+	noCopy bool
+}
+
+func (d *decoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidEncoding}, args...)...)
+}
+
+func (d *decoder) version() error {
+	if len(d.b) == 0 {
+		return d.errorf("empty data")
+	}
+	if d.b[0] != binaryVersion {
+		return d.errorf("unsupported version %d", d.b[0])
+	}
+	d.b = d.b[1:]
+	return nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	if len(d.b) == 0 {
+		return 0, d.errorf("unexpected end of data")
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v, nil
+}
+
+// length decodes a uvarint length of items of `size` bytes, checking that
+// there is enough data for them.
+func (d *decoder) length(size int) (int, error) {
+	n, l := binary.Uvarint(d.b)
+	if l <= 0 {
+		return 0, d.errorf("invalid length")
+	}
+	d.b = d.b[l:]
+	if n > uint64(len(d.b)/size) {
+		return 0, d.errorf("length %d exceeds data", n)
+	}
+	return int(n), nil
+}
+
+// runes decodes `n` runes of the given width code.
+func (d *decoder) runes(code byte, n int) ([]rune, error) {
+	w := widthBytes(code)
+	if len(d.b) < n*w {
+		return nil, d.errorf("unexpected end of data")
+	}
+	rs := make([]rune, n)
+	for i := range rs {
+		switch w {
+		case 1:
+			rs[i] = rune(d.b[0])
+		case 2:
+			rs[i] = rune(binary.LittleEndian.Uint16(d.b))
+		default:
+			rs[i] = rune(binary.LittleEndian.Uint32(d.b))
+		}
+		if rs[i] < 0 || rs[i] > utf8.MaxRune {
+			return nil, d.errorf("invalid rune 0x%x", uint32(rs[i]))
+		}
+		d.b = d.b[w:]
+	}
+	return rs, nil
+}
+
+func (d *decoder) value() (MinMaxSet, error) {
+	tag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	return d.payload(tag)
+}
+
+func (d *decoder) payload(tag byte) (MinMaxSet, error) {
+	kind, code := tag>>2, tag&3
+	switch kind {
+	case kindUnion, kindSortedUnion:
+		return d.union(code, kind == kindSortedUnion)
+	case kindLinearSlice, kindBinarySlice:
+		return d.slice(kind == kindLinearSlice, code)
+	case kindInterval:
+		rs, err := d.runes(code, 2)
+		if err != nil {
+			return nil, err
+		}
+		if rs[0] > rs[1] {
+			return nil, d.errorf("interval From 0x%x > To 0x%x", rs[0], rs[1])
+		}
+		return byWidthCode(code, newInterval[uint8], newInterval[uint16], newInterval[uint32], newInterval[rune])(rs[0], rs[1]), nil
+	case kindUniform:
+		rs, err := d.runes(code, 3)
+		if err != nil {
+			return nil, err
+		}
+		if rs[0] > rs[1] || rs[2] == 0 || (rs[1]-rs[0])%rs[2] != 0 {
+			return nil, d.errorf("invalid uniform Lo=0x%x, Hi=0x%x, Stride=%d", rs[0], rs[1], rs[2])
+		}
+		return byWidthCode(code, newUniform[uint8], newUniform[uint16], newUniform[uint32], newUniform[rune])(rs[0], rs[1], rs[2]), nil
+	case kindBitmap:
+		return d.bitmap(code)
+	case kindRangeSlice:
+		return d.rangeSlice(code)
+	case kindStridedRanges:
+		return d.stridedRanges(code)
+	case kindTwoLevel:
+		return d.twoLevel(code)
+	case kindSparseBitmap:
+		return d.sparseBitmap(code)
+	case kindRoaring:
+		return d.roaring(code)
+	case kindMixedUnion:
+		return d.mixedUnion(code)
+	default:
+		return nil, d.errorf("unknown tag 0x%x", tag)
+	}
+}
+
+func (d *decoder) union(code byte, sorted bool) (MinMaxSet, error) {
+	if code != 0 {
+		return nil, d.errorf("invalid union tag")
+	}
+	if d.depth++; d.depth > maxBinaryDepth {
+		return nil, d.errorf("too many nested unions")
+	}
+	defer func() { d.depth-- }()
+
+	memberTag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	n, err := d.length(1)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]MinMaxSet, n)
+	for i := range members {
+		if memberTag != 0 {
+			members[i], err = d.payload(memberTag)
+		} else {
+			members[i], err = d.value()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	// the first and last members hold the bounds, but empty members may be
+	// anywhere
+	lo, hi := uint32(MaxUint32), uint32(0)
+	for _, m := range members {
+		if m.Min() != MaxUint32 {
+			lo = m.Min()
+			break
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		if members[i].Min() != MaxUint32 {
+			hi = members[i].Max()
+			break
+		}
+	}
+	for _, m := range members {
+		if m.Min() != MaxUint32 && (m.Min() < lo || m.Max() > hi) {
+			return nil, d.errorf("union members out of order")
+		}
+	}
+
+	if memberTag == 0 {
+		return castUnion[MinMaxSet](members, sorted)
+	}
+	newUnion, ok := homogeneousUnions[memberTag]
+	if !ok {
+		return nil, d.errorf("invalid union member tag 0x%x", memberTag)
+	}
+	return newUnion(members, sorted)
+}
+
+func (d *decoder) slice(linear bool, code byte) (MinMaxSet, error) {
+	n, err := d.length(widthBytes(code))
+	if err != nil {
+		return nil, err
+	}
+	if d.noCopy && code == widthUint8 {
+		s := d.b[:n:n]
+		d.b = d.b[n:]
+		for i := 1; i < len(s); i++ {
+			if s[i-1] >= s[i] {
+				return nil, d.errorf("slice not sorted")
+			}
+		}
+		if linear {
+			return LinearSlice[uint8](s), nil
+		}
+		return BinarySlice[uint8](s), nil
+	}
+	rs, err := d.runes(code, n)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(rs); i++ {
+		if rs[i-1] >= rs[i] {
+			return nil, d.errorf("slice not sorted")
+		}
+	}
+	return byWidthCode(code, newSlice[uint8], newSlice[uint16], newSlice[uint32], newSlice[rune])(linear, rs), nil
+}
+
+func (d *decoder) rangeSlice(code byte) (MinMaxSet, error) {
+	n, err := d.length(2 * widthBytes(code))
+	if err != nil {
+		return nil, err
+	}
+	rs, err := d.runes(code, 2*n)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(rs); i += 2 {
+		if rs[i] > rs[i+1] || i > 0 && rs[i-1] >= rs[i] {
+			return nil, d.errorf("invalid range slice")
+		}
+	}
+	return byWidthCode(code, newRangeSlice[uint8], newRangeSlice[uint16], newRangeSlice[uint32], newRangeSlice[rune])(rs), nil
+}
+
+func (d *decoder) stridedRanges(code byte) (MinMaxSet, error) {
+	n, err := d.length(3 * widthBytes(code))
+	if err != nil {
+		return nil, err
+	}
+	rs, err := d.runes(code, 3*n)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(rs); i += 3 {
+		if rs[i] > rs[i+1] || rs[i+2] == 0 || (rs[i+1]-rs[i])%rs[i+2] != 0 || i > 0 && rs[i-2] >= rs[i] {
+			return nil, d.errorf("invalid strided ranges")
+		}
+	}
+	return byWidthCode(code, newStridedRangesTriples[uint8], newStridedRangesTriples[uint16], newStridedRangesTriples[uint32], newStridedRangesTriples[rune])(rs), nil
+}
+
+func (d *decoder) bitmap(code byte) (MinMaxSet, error) {
+	if code != 0 {
+		return nil, d.errorf("invalid bitmap tag")
+	}
+	n, err := d.length(1)
+	if err != nil {
+		return nil, err
+	}
+	var bm Bitmap
+	if d.noCopy && n > 0 {
+		bm = Bitmap(unsafe.String(&d.b[0], n))
+	} else {
+		bm = Bitmap(d.b[:n])
+	}
+	d.b = d.b[n:]
+	if err := validateBitmap(bm); err != nil {
+		return nil, err
+	}
+	return bm, nil
+}
+
+func (d *decoder) twoLevel(code byte) (MinMaxSet, error) {
+	if code != 0 {
+		return nil, d.errorf("invalid two level tag")
+	}
+	first, l := binary.Uvarint(d.b)
+	if l <= 0 || first > utf8.MaxRune>>blockBits {
+		return nil, d.errorf("invalid two level first block")
+	}
+	d.b = d.b[l:]
+	n, err := d.length(2)
+	if err != nil {
+		return nil, err
+	}
+	x := TwoLevel{First: uint32(first), Index: make([]uint16, n)}
+	for i := range x.Index {
+		x.Index[i] = binary.LittleEndian.Uint16(d.b[2*i:])
+	}
+	d.b = d.b[2*n:]
+	if n, err = d.length(8); err != nil {
+		return nil, err
+	}
+	x.Leaves = make([]uint64, n)
+	for i := range x.Leaves {
+		x.Leaves[i] = binary.LittleEndian.Uint64(d.b[8*i:])
+	}
+	d.b = d.b[8*n:]
+	if err := validateTwoLevel(x); err != nil {
+		return nil, err
+	}
+	x.Ranks = x.ranks()
+	return x, nil
+}
+
+func (d *decoder) sparseBitmap(code byte) (MinMaxSet, error) {
+	if code != 0 {
+		return nil, d.errorf("invalid sparse bitmap tag")
+	}
+	n, err := d.length(2 + 8)
+	if err != nil {
+		return nil, err
+	}
+	x := SparseBitmap{Blocks: make([]uint16, n), Words: make([]uint64, n)}
+	for i := range n {
+		x.Blocks[i] = binary.LittleEndian.Uint16(d.b)
+		x.Words[i] = binary.LittleEndian.Uint64(d.b[2:])
+		d.b = d.b[2+8:]
+		switch {
+		case x.Blocks[i] > utf8.MaxRune>>blockBits:
+			return nil, d.errorf("sparse bitmap block 0x%x out of range", x.Blocks[i])
+		case i > 0 && x.Blocks[i-1] >= x.Blocks[i]:
+			return nil, d.errorf("sparse bitmap blocks not sorted")
+		case x.Words[i] == 0:
+			return nil, d.errorf("sparse bitmap block 0x%x empty", x.Blocks[i])
+		}
+	}
+	x.Ranks = x.ranks()
+	return x, nil
+}
+
+func (d *decoder) roaring(code byte) (MinMaxSet, error) {
+	if code != 0 {
+		return nil, d.errorf("invalid roaring tag")
+	}
+	n, err := d.length(2 + 1 + 1)
+	if err != nil {
+		return nil, err
+	}
+	x := Roaring{Keys: make([]uint16, n), Containers: make([]RoaringContainer, n)}
+	for i := range n {
+		if len(d.b) < 2+1 {
+			return nil, d.errorf("unexpected end of data")
+		}
+		x.Keys[i] = binary.LittleEndian.Uint16(d.b)
+		kind := RoaringKind(d.b[2])
+		d.b = d.b[2+1:]
+		m, err := d.length(2)
+		if err != nil {
+			return nil, err
+		}
+		c := RoaringContainer{Kind: kind, Data: make([]uint16, m)}
+		for j := range c.Data {
+			c.Data[j] = binary.LittleEndian.Uint16(d.b[2*j:])
+		}
+		d.b = d.b[2*m:]
+		switch {
+		case x.Keys[i] > utf8.MaxRune>>roaringBits:
+			return nil, d.errorf("roaring key 0x%x out of range", x.Keys[i])
+		case i > 0 && x.Keys[i-1] >= x.Keys[i]:
+			return nil, d.errorf("roaring keys not sorted")
+		}
+		if err := validateRoaringContainer(c); err != nil {
+			return nil, err
+		}
+		x.Containers[i] = c
+	}
+	x.Ranks = x.ranks()
+	return x, nil
+}
+
+func (d *decoder) mixedUnion(code byte) (MinMaxSet, error) {
+	if code != 0 {
+		return nil, d.errorf("invalid mixed union tag")
+	}
+	n, err := d.length(1 + 3*4)
+	if err != nil {
+		return nil, err
+	}
+	x := MixedUnion{Kinds: make([]MixedKind, n), Bounds: make([]uint32, 2*n), Args: make([]uint32, n)}
+	for i := range n {
+		x.Kinds[i] = MixedKind(d.b[0])
+		x.Bounds[2*i] = binary.LittleEndian.Uint32(d.b[1:])
+		x.Bounds[2*i+1] = binary.LittleEndian.Uint32(d.b[5:])
+		x.Args[i] = binary.LittleEndian.Uint32(d.b[9:])
+		d.b = d.b[1+3*4:]
+	}
+	if n, err = d.length(4); err != nil {
+		return nil, err
+	}
+	x.Runes = make([]uint32, n)
+	for i := range x.Runes {
+		x.Runes[i] = binary.LittleEndian.Uint32(d.b[4*i:])
+	}
+	d.b = d.b[4*n:]
+	if n, err = d.length(8); err != nil {
+		return nil, err
+	}
+	x.Words = make([]uint64, n)
+	for i := range x.Words {
+		x.Words[i] = binary.LittleEndian.Uint64(d.b[8*i:])
+	}
+	d.b = d.b[8*n:]
+	if err := validateMixedUnion(x); err != nil {
+		return nil, err
+	}
+	x.Ranks = x.ranks()
+	return x, nil
+}
+
+// validateMixedUnion checks that the segments of the MixedUnion are sorted and
+// do not overlap, that their bounds are in the set, and that the Runes and
+// Words of its segments are consecutive and used entirely.
+func validateMixedUnion(x MixedUnion) error {
+	var runes, words uint32 // the next elements of Runes and Words
+	for i, kind := range x.Kinds {
+		lo, hi, a := x.Bounds[2*i], x.Bounds[2*i+1], x.Args[i]
+		if lo > hi || hi > utf8.MaxRune || i > 0 && x.Bounds[2*i-1] >= lo {
+			return fmt.Errorf("%w: invalid mixed union bounds", ErrInvalidEncoding)
+		}
+		var valid bool
+		switch kind {
+		case MixedInterval:
+			valid = a == 0
+		case MixedStrided:
+			valid = a > 0 && (hi-lo)%a == 0
+		case MixedSlice:
+			j := a
+			for j < uint32(len(x.Runes)) && x.Runes[j] <= hi && (j == a || x.Runes[j-1] < x.Runes[j]) {
+				j++
+			}
+			valid = a == runes && j > a && x.Runes[a] == lo && x.Runes[j-1] == hi
+			runes = j
+		case MixedBitmap:
+			n := (hi-lo)>>blockBits + 1
+			valid = a == words && uint64(a)+uint64(n) <= uint64(len(x.Words)) &&
+				x.Words[a]&1 != 0 && x.Words[a+n-1]>>((hi-lo)&blockMask) == 1
+			words = a + n
+		default:
+			return fmt.Errorf("%w: unknown mixed union segment kind %d", ErrInvalidEncoding, kind)
+		}
+		if !valid {
+			return fmt.Errorf("%w: invalid mixed union segment %d", ErrInvalidEncoding, i)
+		}
+	}
+	if runes != uint32(len(x.Runes)) || words != uint32(len(x.Words)) {
+		return fmt.Errorf("%w: unused mixed union runes or words", ErrInvalidEncoding)
+	}
+	return nil
+}
+
+// validateRoaringContainer checks that the container is not empty, and that
+// its Data is valid for its kind.
+func validateRoaringContainer(c RoaringContainer) error {
+	if len(c.Data) == 0 {
+		return fmt.Errorf("%w: empty roaring container", ErrInvalidEncoding)
+	}
+	switch c.Kind {
+	case RoaringArray:
+		for i, v := range c.Data {
+			if v > roaringMask || i > 0 && c.Data[i-1] >= v {
+				return fmt.Errorf("%w: invalid roaring array", ErrInvalidEncoding)
+			}
+		}
+	case RoaringBitmap:
+		if len(c.Data) != roaringWords || c.len() == 0 {
+			return fmt.Errorf("%w: invalid roaring bitmap", ErrInvalidEncoding)
+		}
+	case RoaringRuns:
+		if len(c.Data)%2 != 0 {
+			return fmt.Errorf("%w: invalid roaring runs", ErrInvalidEncoding)
+		}
+		for i := 0; i < len(c.Data); i += 2 {
+			if c.Data[i] > c.Data[i+1] || c.Data[i+1] > roaringMask ||
+				i > 0 && int(c.Data[i-1])+1 >= int(c.Data[i]) {
+				return fmt.Errorf("%w: invalid roaring runs", ErrInvalidEncoding)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown roaring container kind %d", ErrInvalidEncoding, c.Kind)
+	}
+	return nil
+}
+
+// validateTwoLevel checks that the Index of the TwoLevel references its
+// Leaves, and that its first and last blocks are not empty.
+func validateTwoLevel(x TwoLevel) error {
+	for _, j := range x.Index {
+		if int(j) >= len(x.Leaves) {
+			return fmt.Errorf("%w: two level leaf %d out of range", ErrInvalidEncoding, j)
+		}
+	}
+	switch n := len(x.Index); {
+	case n == 0:
+		return nil
+	case x.leaf(0) == 0 || x.leaf(n-1) == 0:
+		return fmt.Errorf("%w: two level first or last block empty", ErrInvalidEncoding)
+	case x.Max() > utf8.MaxRune:
+		return fmt.Errorf("%w: two level max rune 0x%x", ErrInvalidEncoding, x.Max())
+	}
+	return nil
+}
+
+// validateBitmap checks that the header of the Bitmap is consistent with its
+// body.
+func validateBitmap(x Bitmap) error {
+	switch {
+	case len(x) == 0:
+		return nil
+	case len(x) <= bmHdrLen:
+		return fmt.Errorf("%w: bitmap too short", ErrInvalidEncoding)
+	case x.Max() > utf8.MaxRune:
+		return fmt.Errorf("%w: bitmap max rune 0x%x", ErrInvalidEncoding, x.Max())
+	case x[bmHdrLen]&1 == 0:
+		return fmt.Errorf("%w: bitmap min rune not set", ErrInvalidEncoding)
+	case x[len(x)-1]>>(x[2]>>bmPosShift) != 1:
+		return fmt.Errorf("%w: bitmap max rune not set", ErrInvalidEncoding)
+	}
+	return nil
+}
+
+// byWidthCode returns the function for the RuneT with the given code.
+func byWidthCode[F any](code byte, f8, f16, f32, fRune F) F {
+	return [...]F{f8, f16, f32, fRune}[code&3]
+}
+
+// homogeneousUnions has constructors of Unions and SortedUnions of concrete
+// types, indexed by the tag of their members. Members that are Unions or
+// SortedUnions share the same tag but may have different types, so they are
+// kept in a Union[MinMaxSet] or SortedUnion[MinMaxSet].
+var homogeneousUnions = map[byte]func(members []MinMaxSet, sorted bool) (MinMaxSet, error){
+	kindLinearSlice<<2 | widthUint8:    castUnion[LinearSlice[uint8]],
+	kindLinearSlice<<2 | widthUint16:   castUnion[LinearSlice[uint16]],
+	kindLinearSlice<<2 | widthUint32:   castUnion[LinearSlice[uint32]],
+	kindLinearSlice<<2 | widthRune:     castUnion[LinearSlice[rune]],
+	kindBinarySlice<<2 | widthUint8:    castUnion[BinarySlice[uint8]],
+	kindBinarySlice<<2 | widthUint16:   castUnion[BinarySlice[uint16]],
+	kindBinarySlice<<2 | widthUint32:   castUnion[BinarySlice[uint32]],
+	kindBinarySlice<<2 | widthRune:     castUnion[BinarySlice[rune]],
+	kindInterval<<2 | widthUint8:       castUnion[Interval[uint8]],
+	kindInterval<<2 | widthUint16:      castUnion[Interval[uint16]],
+	kindInterval<<2 | widthUint32:      castUnion[Interval[uint32]],
+	kindInterval<<2 | widthRune:        castUnion[Interval[rune]],
+	kindUniform<<2 | widthUint8:        castUnion[Uniform[uint8]],
+	kindUniform<<2 | widthUint16:       castUnion[Uniform[uint16]],
+	kindUniform<<2 | widthUint32:       castUnion[Uniform[uint32]],
+	kindUniform<<2 | widthRune:         castUnion[Uniform[rune]],
+	kindRangeSlice<<2 | widthUint8:     castUnion[RangeSlice[uint8]],
+	kindRangeSlice<<2 | widthUint16:    castUnion[RangeSlice[uint16]],
+	kindRangeSlice<<2 | widthUint32:    castUnion[RangeSlice[uint32]],
+	kindRangeSlice<<2 | widthRune:      castUnion[RangeSlice[rune]],
+	kindStridedRanges<<2 | widthUint8:  castUnion[StridedRanges[uint8]],
+	kindStridedRanges<<2 | widthUint16: castUnion[StridedRanges[uint16]],
+	kindStridedRanges<<2 | widthUint32: castUnion[StridedRanges[uint32]],
+	kindStridedRanges<<2 | widthRune:   castUnion[StridedRanges[rune]],
+	kindBitmap << 2:                    castUnion[Bitmap],
+	kindTwoLevel << 2:                  castUnion[TwoLevel],
+	kindSparseBitmap << 2:              castUnion[SparseBitmap],
+	kindRoaring << 2:                   castUnion[Roaring],
+	kindMixedUnion << 2:                castUnion[MixedUnion],
+	kindUnion << 2:                     castUnion[MinMaxSet],
+	kindSortedUnion << 2:               castUnion[MinMaxSet],
+}
+
+// newRangeSlice returns a RangeSlice with the given pairs of From and To.
+func newRangeSlice[T RuneT](pairs []rune) MinMaxSet {
+	x := make(RangeSlice[T], len(pairs)/2)
+	for i := range x {
+		x[i] = Interval[T]{T(pairs[2*i]), T(pairs[2*i+1])}
+	}
+	return x
+}
+
+// newStridedRangesTriples returns a StridedRanges with the given triples of
+// Lo, Hi and Stride.
+func newStridedRangesTriples[T RuneT](triples []rune) MinMaxSet {
+	x := make(StridedRanges[T], len(triples)/3)
+	for i := range x {
+		x[i] = Uniform[T]{T(triples[3*i]), T(triples[3*i+1]), T(triples[3*i+2])}
+	}
+	return x
+}
+
+// castUnion returns a Union[T], or a SortedUnion[T] if `sorted`, with the
+// given members, which must be of type T.
+func castUnion[T MinMaxSet](members []MinMaxSet, sorted bool) (MinMaxSet, error) {
+	u := make(Union[T], len(members))
+	for i := range members {
+		u[i] = members[i].(T)
+	}
+	if !sorted {
+		return u, nil
+	}
+	res, err := NewSortedUnion(u...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+	return res, nil
+}
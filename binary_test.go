@@ -0,0 +1,311 @@
+package runes
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"unicode"
+
+	"github.com/diegommm/runes/util"
+)
+
+var _ = []interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryAppender
+}{
+	Union[MinMaxSet]{},
+	SortedUnion[MinMaxSet]{},
+	LinearSlice[uint8]{},
+	BinarySlice[uint16]{},
+	RangeSlice[uint8]{},
+	StridedRanges[uint8]{},
+	Interval[uint32]{},
+	Uniform[rune]{},
+	Bitmap(""),
+	TwoLevel{},
+	SparseBitmap{},
+	Roaring{},
+	MixedUnion{},
+}
+
+var _ = []encoding.BinaryUnmarshaler{
+	new(Union[MinMaxSet]),
+	new(SortedUnion[MinMaxSet]),
+	new(LinearSlice[uint8]),
+	new(BinarySlice[uint16]),
+	new(RangeSlice[uint8]),
+	new(StridedRanges[uint8]),
+	new(Interval[uint32]),
+	new(Uniform[rune]),
+	new(Bitmap),
+	new(TwoLevel),
+	new(SparseBitmap),
+	new(Roaring),
+	new(MixedUnion),
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	t.Parallel()
+	testCases := []MinMaxSet{
+		Union[MinMaxSet](nil),
+		Union[MinMaxSet]{Interval[uint8]{1, 3}, NewBitmap([]rune{5, 9}), LinearSlice[rune]{0x10000}},
+		Union[MinMaxSet]{LinearSlice[rune]{}, Interval[rune]{'a', 'z'}},
+		Union[MinMaxSet]{Interval[rune]{'a', 'z'}, LinearSlice[rune]{}},
+		Union[Interval[uint16]]{{1, 3}, {0x100, 0x200}},
+		Union[Bitmap]{NewBitmap([]rune{1, 3}), NewBitmap([]rune{0x100, 0x200})},
+		Union[MinMaxSet]{
+			Union[Interval[uint8]]{{1, 3}},
+			Union[Bitmap]{NewBitmap([]rune{5, 9})},
+		},
+		MustSortedUnion[MinMaxSet](),
+		MustSortedUnion[MinMaxSet](Interval[uint8]{1, 3}, NewBitmap([]rune{5, 9}), LinearSlice[rune]{0x10000}),
+		MustSortedUnion(Interval[uint16]{1, 3}, Interval[uint16]{0x100, 0x200}),
+		Union[MinMaxSet]{MustSortedUnion(NewBitmap([]rune{1, 3}), NewBitmap([]rune{0x100, 0x200}))},
+		LinearSlice[uint8](nil),
+		LinearSlice[uint8]{1, 2, 255},
+		LinearSlice[uint16]{1, 2, 0xffff},
+		LinearSlice[uint32]{1, 2, 0x10ffff},
+		LinearSlice[rune]{1, 2, 0x10ffff},
+		BinarySlice[uint8]{1, 2, 255},
+		BinarySlice[uint16]{1, 2, 0xffff},
+		BinarySlice[uint32]{1, 2, 0x10ffff},
+		BinarySlice[rune]{1, 2, 0x10ffff},
+		RangeSlice[uint8](nil),
+		RangeSlice[uint8]{{1, 2}, {3, 3}, {9, 255}},
+		RangeSlice[uint16]{{1, 2}, {9, 0xffff}},
+		RangeSlice[uint32]{{1, 2}, {9, 0x10ffff}},
+		RangeSlice[rune]{{1, 2}, {9, 0x10ffff}},
+		Union[RangeSlice[uint8]]{{{1, 2}}, {{9, 10}}},
+		StridedRanges[uint8](nil),
+		StridedRanges[uint8]{{1, 2, 1}, {3, 9, 3}, {10, 255, 5}},
+		StridedRanges[uint8]{{1, 4, 2}, {6, 6, 1}},
+		StridedRanges[uint16]{{1, 2, 1}, {9, 0xffff, 2}},
+		StridedRanges[uint32]{{1, 2, 1}, {9, 0x10ffff, 2}},
+		StridedRanges[rune]{{1, 2, 1}, {9, 0x10ffff, 2}},
+		Union[StridedRanges[uint8]]{{{1, 2, 1}}, {{9, 13, 2}}},
+		Interval[uint8]{'a', 'z'},
+		Interval[uint16]{'a', 0xffff},
+		Interval[uint32]{'a', 0x10ffff},
+		Interval[rune]{'a', 0x10ffff},
+		Uniform[uint8]{3, 31, 7},
+		Uniform[uint8]{10, 13, 2},
+		Uniform[uint16]{3, 0xfffa, 7},
+		Uniform[uint32]{3, 0x10ffef, 7},
+		Uniform[rune]{3, 0x10ffef, 7},
+		NewBitmap(nil),
+		NewBitmap([]rune{1, 3, 99, 410}),
+		NewBitmap([]rune{0x10ffff}),
+		NewTwoLevel(nil),
+		NewTwoLevel([]rune{1, 63, 64, 200, 0x10000}),
+		NewTwoLevel([]rune{0x10ffff}),
+		Union[TwoLevel]{NewTwoLevel([]rune{1, 3}), NewTwoLevel([]rune{0x100, 0x200})},
+		NewSparseBitmap(nil),
+		NewSparseBitmap([]rune{1, 63, 64, 200, 0x10000}),
+		NewSparseBitmap([]rune{0x10ffff}),
+		Union[SparseBitmap]{NewSparseBitmap([]rune{1, 3}), NewSparseBitmap([]rune{0x100, 0x200})},
+		NewRoaring(nil),
+		NewRoaring(slices.Collect(util.Concat(runes(1, 3, 99), util.Seq(0x1000, 0x1fff, 1), util.Seq(0x2000, 0x2fff, 3), util.Seq(0x10000, 0x10100, 1)))),
+		NewRoaring([]rune{0x10ffff}),
+		Union[Roaring]{NewRoaring([]rune{1, 3}), NewRoaring([]rune{0x100, 0x200})},
+		MixedUnion{},
+		mixedUnion,
+		NewMixedUnion(slices.Collect(util.RangeTableIter(unicode.L))),
+		MustSortedUnion(mixedUnion, NewMixedUnion([]rune{0x10000, 0x10002})),
+		FromRangeTable(unicode.Letter),
+		Compile(slices.Collect(util.RangeTableIter(unicode.Greek)), StepCost(0)),
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			data, err := tc.(encoding.BinaryMarshaler).MarshalBinary()
+			util.MustEqual(t, nil, err, "MarshalBinary")
+
+			got, err := Decode(data)
+			util.MustEqual(t, nil, err, "Decode")
+			util.Equal(t, true, Equal(tc, got), "Decode: sets differ")
+			util.Equal(t, fmt.Sprintf("%T", tc), fmt.Sprintf("%T", got), "Decode: type")
+			// Equal compares ranges, so check Contains at their boundaries too
+			for lo, hi := range setRanges(tc) {
+				util.Equal(t, true, got.Contains(lo) && got.Contains(hi), "Decode: Contains(0x%x, 0x%x)", lo, hi)
+				util.Equal(t, false, got.Contains(lo-1) || got.Contains(hi+1), "Decode: Contains(0x%x, 0x%x)", lo-1, hi+1)
+			}
+
+			appended, err := tc.(encoding.BinaryAppender).AppendBinary([]byte("prefix"))
+			util.MustEqual(t, nil, err, "AppendBinary")
+			util.Equal(t, "prefix"+string(data), string(appended), "AppendBinary")
+		})
+	}
+}
+
+func TestBinaryUnmarshal(t *testing.T) {
+	t.Parallel()
+	data, err := Interval[uint16]{1, 0x100}.MarshalBinary()
+	util.MustEqual(t, nil, err, "MarshalBinary")
+
+	var interval Interval[uint16]
+	util.Equal(t, nil, interval.UnmarshalBinary(data), "same type")
+	util.Equal(t, Interval[uint16]{1, 0x100}, interval, "unexpected value")
+
+	var otherWidth Interval[uint32]
+	err = otherWidth.UnmarshalBinary(data)
+	util.Equal(t, true, errors.Is(err, ErrInvalidEncoding), "other width: %v", err)
+
+	data, err = Union[Interval[uint16]]{{1, 3}, {0x100, 0x200}}.MarshalBinary()
+	util.MustEqual(t, nil, err, "MarshalBinary")
+
+	var union Union[MinMaxSet]
+	util.Equal(t, nil, union.UnmarshalBinary(data), "compatible member type")
+	util.Equal(t, true, Equal(Union[Interval[uint16]]{{1, 3}, {0x100, 0x200}}, union), "unexpected value")
+
+	var bitmaps Union[Bitmap]
+	err = bitmaps.UnmarshalBinary(data)
+	util.Equal(t, true, errors.Is(err, ErrInvalidEncoding), "incompatible member type: %v", err)
+}
+
+func TestBinaryUnsupported(t *testing.T) {
+	t.Parallel()
+	_, err := Union[MinMaxSet]{minMaxFunc{}}.MarshalBinary()
+	util.Equal(t, true, errors.Is(err, ErrUnsupportedSet), "unexpected error: %v", err)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	t.Parallel()
+	const (
+		interval8  = kindInterval<<2 | widthUint8
+		interval32 = kindInterval<<2 | widthUint32
+		uniform8   = kindUniform<<2 | widthUint8
+		linear8    = kindLinearSlice<<2 | widthUint8
+		union      = kindUnion << 2
+		sorted     = kindSortedUnion << 2
+		bitmap     = kindBitmap << 2
+		ranges8    = kindRangeSlice<<2 | widthUint8
+		twoLevel   = kindTwoLevel << 2
+		sparse     = kindSparseBitmap << 2
+		roaring    = kindRoaring << 2
+		mixed      = kindMixedUnion << 2
+		strided8   = kindStridedRanges<<2 | widthUint8
+	)
+	nested := []byte{binaryVersion}
+	for range maxBinaryDepth + 1 {
+		nested = append(nested, union, 0, 1)
+	}
+
+	testCases := [][]byte{
+		nil,
+		{2, interval8, 1, 2},
+		{binaryVersion},
+		{binaryVersion, 0},
+		{binaryVersion, 0xff},
+		{binaryVersion, interval8, 1},
+		{binaryVersion, interval8, 2, 1},
+		{binaryVersion, interval8, 1, 2, 3},
+		{binaryVersion, interval32, 0, 0, 0, 0, 0, 0, 0x11, 0},
+		{binaryVersion, uniform8, 1, 2, 0},
+		{binaryVersion, uniform8, 2, 1, 1},
+		{binaryVersion, uniform8, 1, 4, 2},
+		{binaryVersion, linear8, 3, 1, 2},
+		{binaryVersion, linear8, 2, 2, 1},
+		{binaryVersion, linear8, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+		{binaryVersion, ranges8, 1, 2, 1},
+		{binaryVersion, ranges8, 2, 1, 3, 3, 4},
+		{binaryVersion, ranges8, 2, 1, 3},
+		{binaryVersion, strided8, 1, 2, 1, 1},
+		{binaryVersion, strided8, 1, 1, 2, 0},
+		{binaryVersion, strided8, 2, 1, 3, 1, 3, 4, 1},
+		{binaryVersion, strided8, 2, 1, 2},
+		{binaryVersion, strided8, 1, 1, 4, 2},
+		{binaryVersion, bitmap, 2, 0, 0},
+		{binaryVersion, bitmap, 4, 1, 0, 0, 0},
+		{binaryVersion, bitmap, 4, 1, 0, 0x20, 1},
+		{binaryVersion, bitmap, 4, 0, 0, 0x20, 0x10},
+		{binaryVersion, bitmap | 1, 0},
+		{binaryVersion, roaring | 1, 0},
+		{binaryVersion, roaring, 1, 0, 0, byte(RoaringArray), 0},
+		{binaryVersion, roaring, 1, 0, 0, 3, 1, 1, 0},
+		{binaryVersion, roaring, 1, 0x10, 0x01, byte(RoaringArray), 1, 1, 0},
+		{binaryVersion, roaring, 2, 1, 0, byte(RoaringArray), 1, 1, 0, 1, 0, byte(RoaringArray), 1, 1, 0},
+		{binaryVersion, roaring, 1, 0, 0, byte(RoaringArray), 2, 2, 0, 1, 0},
+		{binaryVersion, roaring, 1, 0, 0, byte(RoaringArray), 1, 0, 0x10},
+		{binaryVersion, roaring, 1, 0, 0, byte(RoaringRuns), 1, 1, 0},
+		{binaryVersion, roaring, 1, 0, 0, byte(RoaringRuns), 2, 2, 0, 1, 0},
+		{binaryVersion, roaring, 1, 0, 0, byte(RoaringRuns), 4, 1, 0, 2, 0, 3, 0, 4, 0},
+		{binaryVersion, roaring, 1, 0, 0, byte(RoaringBitmap), 1, 1, 0},
+		{binaryVersion, roaring, 1, 0, 0, byte(RoaringArray), 2, 1, 0},
+		{binaryVersion, sparse | 1, 0},
+		{binaryVersion, sparse, 1, 0, 0, 1},
+		{binaryVersion, sparse, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{binaryVersion, sparse, 1, 0, 0x44, 1, 0, 0, 0, 0, 0, 0, 0},
+		{binaryVersion, sparse, 2, 1, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0, 0},
+		{binaryVersion, twoLevel | 1, 0, 0, 0},
+		{binaryVersion, twoLevel, 0x80, 0x89, 0x01, 0, 0},
+		{binaryVersion, twoLevel, 0, 2, 1, 0},
+		{binaryVersion, twoLevel, 0, 1, 1, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0},
+		{binaryVersion, twoLevel, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0},
+		{binaryVersion, twoLevel, 0, 2, 0, 0, 1, 0, 2, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{binaryVersion, twoLevel, 0xff, 0x87, 0x01, 2, 0, 0, 0, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0},
+		{binaryVersion, union | 1, 0, 0},
+		{binaryVersion, union, 0, 2, interval8, 5, 6, interval8, 1, 2},
+		{binaryVersion, union, 0xff, 1, 0},
+		{binaryVersion, mixed | 1, 0, 0, 0},
+		{binaryVersion, mixed, 1, byte(MixedInterval), 1, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0},
+		{binaryVersion, mixed, 0, 0},
+		{binaryVersion, sorted | 1, 0, 0},
+		{binaryVersion, sorted, interval8, 2, 1, 3, 3, 4},
+		{binaryVersion, sorted, 0, 2, interval8, 1, 3, linear8, 2, 2, 4},
+		nested,
+	}
+
+	for i, data := range testCases {
+		_, err := Decode(data)
+		util.Equal(t, true, errors.Is(err, ErrInvalidEncoding), "index=%v; unexpected error: %v", i, err)
+	}
+}
+
+func TestDecodeInvalidMixedUnion(t *testing.T) {
+	t.Parallel()
+	segment := func(kind MixedKind, lo, hi, arg uint32) MixedUnion {
+		return MixedUnion{Kinds: []MixedKind{kind}, Bounds: []uint32{lo, hi}, Args: []uint32{arg}}
+	}
+	withRunes := func(x MixedUnion, rs ...uint32) MixedUnion {
+		x.Runes = rs
+		return x
+	}
+	withWords := func(x MixedUnion, ws ...uint64) MixedUnion {
+		x.Words = ws
+		return x
+	}
+	testCases := []MixedUnion{
+		segment(4, 1, 2, 0),
+		segment(MixedInterval, 2, 1, 0),
+		segment(MixedInterval, 1, maxRune+1, 0),
+		segment(MixedInterval, 1, 2, 1),
+		segment(MixedStrided, 1, 2, 0),
+		segment(MixedStrided, 1, 4, 2),
+		segment(MixedSlice, 1, 2, 0),
+		withRunes(segment(MixedSlice, 1, 3, 0), 1, 2),
+		withRunes(segment(MixedSlice, 1, 3, 0), 1, 3, 5),
+		withRunes(segment(MixedSlice, 1, 3, 0), 3, 1, 3),
+		withRunes(segment(MixedSlice, 1, 3, 1), 0, 1, 3),
+		segment(MixedBitmap, 1, 2, 0),
+		withWords(segment(MixedBitmap, 1, 2, 0), 1),
+		withWords(segment(MixedBitmap, 1, 2, 0), 7),
+		withWords(segment(MixedBitmap, 1, 2, 0), 3, 1),
+		withWords(segment(MixedBitmap, 1, 65, 0), 1),
+		withWords(segment(MixedBitmap, 1, 65, 1), 1, 1),
+		{
+			Kinds:  []MixedKind{MixedInterval, MixedInterval},
+			Bounds: []uint32{1, 3, 3, 4},
+			Args:   []uint32{0, 0},
+		},
+	}
+
+	for i, x := range testCases {
+		data, err := x.MarshalBinary()
+		util.MustEqual(t, nil, err, "index=%v; MarshalBinary", i)
+		_, err = Decode(data)
+		util.Equal(t, true, errors.Is(err, ErrInvalidEncoding), "index=%v; unexpected error: %v", i, err)
+	}
+}
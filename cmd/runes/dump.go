@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/diegommm/runes"
+	"github.com/diegommm/runes/util"
+)
+
+// formats are the output formats of dump, by name.
+var formats = map[string]struct {
+	ext   string
+	write func(w io.Writer, name string, s runes.MinMaxSet) error
+}{
+	"decimal": {".txt", writeDecimal},
+	"hex":     {".txt", writeHex},
+	"ranges":  {".txt", writeRanges},
+	"json":    {".json", writeJSON},
+	"csv":     {".csv", writeCSV},
+	"binary":  {".bin", writeBinary},
+}
+
+func dump(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	table := fs.String("table", "", "name of a table of the catalogue to dump")
+	all := fs.Bool("all", false, "dump all the tables of the catalogue, one file per table in -o")
+	setFile := fs.String("set", "", "file with a set encoded in the binary format of package runes")
+	format := fs.String("format", "decimal", "output format: "+strings.Join(slices.Sorted(maps.Keys(formats)), ", "))
+	out := fs.String("o", "", "output file, or directory with -all (default stdout, or the current directory with -all)")
+	list := fs.Bool("list", false, "list the names of the tables of the catalogue")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %q", fs.Args())
+	}
+	f, ok := formats[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	switch {
+	case *list:
+		for _, name := range slices.Sorted(maps.Keys(util.Tables)) {
+			fmt.Fprintln(stdout, name)
+		}
+		return nil
+	case *all:
+		return dumpAll(*out, *format)
+	}
+
+	var name string
+	var s runes.MinMaxSet
+	switch {
+	case *table != "" && *setFile != "":
+		return errors.New("only one of -table or -set can be used")
+	case *table != "":
+		rt, ok := util.Tables[*table]
+		if !ok {
+			return fmt.Errorf("unknown table %q; use -list to see the catalogue", *table)
+		}
+		name, s = *table, runes.FromRangeTable(rt)
+	case *setFile != "":
+		data, err := os.ReadFile(*setFile)
+		if err != nil {
+			return err
+		}
+		if s, err = runes.Decode(data); err != nil {
+			return fmt.Errorf("%s: %w", *setFile, err)
+		}
+		name = strings.TrimSuffix(filepath.Base(*setFile), filepath.Ext(*setFile))
+	default:
+		return errors.New("one of -table, -set, -all or -list is required")
+	}
+
+	if *out == "" {
+		return f.write(stdout, name, s)
+	}
+	return writeFile(*out, name, s, f.write)
+}
+
+// dumpAll writes every table of the catalogue to a file in `dir`, named after
+// the table in lower case.
+func dumpAll(dir, format string) error {
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f := formats[format]
+	for name, rt := range util.Tables {
+		path := filepath.Join(dir, strings.ToLower(name)+f.ext)
+		if err := writeFile(path, name, runes.FromRangeTable(rt), f.write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path, name string, s runes.MinMaxSet, write func(io.Writer, string, runes.MinMaxSet) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, name, s); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// writeLines writes a line for each rune or range of `s`, using `runeLine` or
+// `rangeLine` respectively, whichever is not nil.
+func writeLines(w io.Writer, s runes.MinMaxSet, runeLine func(rune) string, rangeLine func(lo, hi rune) string) error {
+	bw := bufio.NewWriter(w)
+	e := s.(runes.Enumerable)
+	if runeLine != nil {
+		for r := range e.All() {
+			bw.WriteString(runeLine(r))
+			bw.WriteByte('\n')
+		}
+	} else {
+		for lo, hi := range e.Ranges() {
+			bw.WriteString(rangeLine(lo, hi))
+			bw.WriteByte('\n')
+		}
+	}
+	// bufio.Writer keeps the first error, so checking Flush is enough
+	return bw.Flush()
+}
+
+// writeDecimal writes one decimal rune per line.
+func writeDecimal(w io.Writer, _ string, s runes.MinMaxSet) error {
+	return writeLines(w, s, func(r rune) string {
+		return strconv.Itoa(int(r))
+	}, nil)
+}
+
+// writeHex writes one rune per line in upper case hexadecimal, with at least
+// four digits.
+func writeHex(w io.Writer, _ string, s runes.MinMaxSet) error {
+	return writeLines(w, s, func(r rune) string {
+		return fmt.Sprintf("%04X", r)
+	}, nil)
+}
+
+// writeRanges writes one range per line as "U+XXXX..U+YYYY", or "U+XXXX" if it
+// has a single rune.
+func writeRanges(w io.Writer, _ string, s runes.MinMaxSet) error {
+	return writeLines(w, s, nil, func(lo, hi rune) string {
+		if lo == hi {
+			return fmt.Sprintf("%U", lo)
+		}
+		return fmt.Sprintf("%U..%U", lo, hi)
+	})
+}
+
+// writeJSON writes an object with the name of the set and its ranges as pairs
+// of decimal runes.
+func writeJSON(w io.Writer, name string, s runes.MinMaxSet) error {
+	v := struct {
+		Name   string    `json:"name"`
+		Ranges [][2]rune `json:"ranges"`
+	}{Name: name, Ranges: [][2]rune{}}
+	for lo, hi := range s.(runes.Enumerable).Ranges() {
+		v.Ranges = append(v.Ranges, [2]rune{lo, hi})
+	}
+	return json.NewEncoder(w).Encode(v)
+}
+
+// writeCSV writes a header and one range per row, with its first and last
+// runes in decimal.
+func writeCSV(w io.Writer, _ string, s runes.MinMaxSet) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"first", "last"})
+	for lo, hi := range s.(runes.Enumerable).Ranges() {
+		cw.Write([]string{strconv.Itoa(int(lo)), strconv.Itoa(int(hi))})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeBinary writes the set in the binary format of package runes.
+func writeBinary(w io.Writer, _ string, s runes.MinMaxSet) error {
+	data, err := s.(interface{ MarshalBinary() ([]byte, error) }).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diegommm/runes"
+	"github.com/diegommm/runes/util"
+)
+
+func TestDumpFormats(t *testing.T) {
+	t.Parallel()
+	// ASCII_Hex_Digit is 0-9, A-F and a-f
+	testCases := []struct {
+		format, expected string
+	}{
+		{"decimal", "48\n49\n"},
+		{"hex", "0030\n0031\n"},
+		{"ranges", "U+0030..U+0039\nU+0041..U+0046\nU+0061..U+0066\n"},
+		{"json", `{"name":"ASCII_Hex_Digit","ranges":[[48,57],[65,70],[97,102]]}` + "\n"},
+		{"csv", "first,last\n48,57\n65,70\n97,102\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			err := run([]string{"dump", "-table", "ASCII_Hex_Digit", "-format", tc.format}, &out, io.Discard)
+			util.MustEqual(t, nil, err, "run")
+			util.Equal(t, true, strings.HasPrefix(out.String(), tc.expected),
+				"unexpected output:\n%s", out.String())
+		})
+	}
+}
+
+func TestDumpBinary(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "greek.bin")
+	err := run([]string{"dump", "-table", "Greek", "-format", "binary", "-o", bin}, io.Discard, io.Discard)
+	util.MustEqual(t, nil, err, "run")
+
+	data, err := os.ReadFile(bin)
+	util.MustEqual(t, nil, err, "read")
+	s, err := runes.Decode(data)
+	util.MustEqual(t, nil, err, "decode")
+	util.Equal(t, true, runes.Equal(runes.FromRangeTable(util.Tables["Greek"]), s), "unexpected set")
+
+	// dump the custom set back as ranges
+	var out bytes.Buffer
+	err = run([]string{"dump", "-set", bin, "-format", "ranges"}, &out, io.Discard)
+	util.MustEqual(t, nil, err, "run")
+	util.Equal(t, true, strings.HasPrefix(out.String(), "U+0370..U+0373\n"),
+		"unexpected output:\n%s", out.String())
+}
+
+func TestDumpAll(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	err := run([]string{"dump", "-all", "-format", "csv", "-o", dir}, io.Discard, io.Discard)
+	util.MustEqual(t, nil, err, "run")
+
+	entries, err := os.ReadDir(dir)
+	util.MustEqual(t, nil, err, "read dir")
+	util.Equal(t, len(util.Tables), len(entries), "number of files")
+	_, err = os.Stat(filepath.Join(dir, "greek.csv"))
+	util.Equal(t, nil, err, "stat")
+}
+
+func TestDumpErrors(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	testCases := [][]string{
+		{},
+		{"nope"},
+		{"dump"},
+		{"dump", "-table", "Nope"},
+		{"dump", "-table", "Greek", "-format", "nope"},
+		{"dump", "-table", "Greek", "-set", "x"},
+		{"dump", "-set", filepath.Join(dir, "nope")},
+		{"dump", "-table", "Greek", "extra"},
+		{"dump", "-nope"},
+	}
+
+	for i, args := range testCases {
+		err := run(args, io.Discard, io.Discard)
+		util.Equal(t, true, err != nil, "index=%v; expected error", i)
+	}
+}
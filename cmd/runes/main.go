@@ -0,0 +1,41 @@
+// Runes is a tool to work with the sets of package runes.
+//
+// Usage:
+//
+//	runes <command> [flags]
+//
+// The commands are:
+//
+//	dump    write the runes of a table or set in different formats
+//
+// Run "runes <command> -h" for the flags of each command.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "runes:", err)
+		os.Exit(1)
+	}
+}
+
+// commands are the subcommands of the tool, by name.
+var commands = map[string]func(args []string, stdout, stderr io.Writer) error{
+	"dump": dump,
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command; usage: runes <command> [flags]")
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd(args[1:], stdout, stderr)
+}
@@ -0,0 +1,321 @@
+// Runesgen generates a Go file declaring a package-level variable with an
+// optimized set literal of package runes, and a test checking it against its
+// source. It is meant to be used with go generate:
+//
+//	//go:generate go run github.com/diegommm/runes/cmd/runesgen -name greek -table Greek
+//
+// The runes of the set are taken from exactly one of the following sources:
+//
+//   - -table: a table of package unicode, by its name in unicode.Categories,
+//     unicode.Scripts or unicode.Properties.
+//   - -file: a file with one decimal rune per line. Empty lines and lines
+//     starting with '#' are ignored.
+//   - -expr: an expression of table names joined with the operators '+'
+//     (union), '-' (difference) and '&' (intersection), which are evaluated
+//     from left to right. For example, "Letter-Latin".
+//
+// The set is built with runes.Compile, so it uses the representation and the
+// narrowest runes.RuneT with the lowest estimated cost.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/diegommm/runes"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "runesgen:", err)
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	table, file, expr string
+	name, pkg, out    string
+	noTest            bool
+	stepCost          int
+}
+
+func run(args []string, stderr io.Writer) error {
+	var c config
+	fs := flag.NewFlagSet("runesgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.table, "table", "", "name of a table of package unicode")
+	fs.StringVar(&c.file, "file", "", "file with one decimal rune per line")
+	fs.StringVar(&c.expr, "expr", "", "expression of table names joined with '+', '-' and '&'")
+	fs.StringVar(&c.name, "name", "", "name of the variable to declare (required)")
+	fs.StringVar(&c.pkg, "pkg", os.Getenv("GOPACKAGE"), "package name of the generated files")
+	fs.StringVar(&c.out, "o", "", "output file (default \"<name>_runes.go\" in lower case)")
+	fs.BoolVar(&c.noTest, "notest", false, "do not generate the test file")
+	fs.IntVar(&c.stepCost, "stepcost", -1, "see runes.StepCost; negative values use its default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %q", fs.Args())
+	}
+	return c.generate()
+}
+
+func (c config) generate() error {
+	if !token.IsIdentifier(c.name) {
+		return fmt.Errorf("invalid variable name %q", c.name)
+	}
+	if !token.IsIdentifier(c.pkg) {
+		return fmt.Errorf("invalid package name %q; use -pkg or run with go generate", c.pkg)
+	}
+	if c.out == "" {
+		c.out = strings.ToLower(c.name) + "_runes.go"
+	}
+	src, err := c.source()
+	if err != nil {
+		return err
+	}
+	var opts []runes.Option
+	if c.stepCost >= 0 {
+		opts = append(opts, runes.StepCost(c.stepCost))
+	}
+	set := runes.Compile(src.runes, opts...)
+
+	code, err := c.code(src, set)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.out, code, 0o644); err != nil {
+		return err
+	}
+	if c.noTest {
+		return nil
+	}
+	test, err := c.testCode(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(strings.TrimSuffix(c.out, ".go")+"_test.go", test, 0o644)
+}
+
+// source is the origin of the runes of a set.
+type source struct {
+	desc  string   // description for the doc comment
+	runes []rune   // sorted
+	test  string   // boolean Go expression of `r` that checks membership
+	list  bool     // whether `test` needs the list of runes in the test file
+	deps  []string // imports needed by `test`
+}
+
+func (c config) source() (source, error) {
+	var set int
+	for _, v := range []string{c.table, c.file, c.expr} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return source{}, errors.New("exactly one of -table, -file or -expr is required")
+	}
+	switch {
+	case c.table != "":
+		return tableSource(c.table)
+	case c.file != "":
+		return fileSource(c.file)
+	default:
+		return exprSource(c.expr)
+	}
+}
+
+func tableSource(name string) (source, error) {
+	rt, expr, err := lookupTable(name)
+	if err != nil {
+		return source{}, err
+	}
+	return source{
+		desc:  "the runes of the unicode table " + name,
+		runes: rangeTableRunes(rt),
+		test:  "unicode.Is(" + expr + ", r)",
+		deps:  []string{"unicode"},
+	}, nil
+}
+
+func fileSource(path string) (source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return source{}, err
+	}
+	defer f.Close()
+	rs, err := parseRunes(f)
+	if err != nil {
+		return source{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return source{
+		desc:  "the runes of the file " + path,
+		runes: rs,
+		list:  true,
+	}, nil
+}
+
+// parseRunes parses one decimal rune per line, ignoring empty lines and lines
+// starting with '#'.
+func parseRunes(r io.Reader) ([]rune, error) {
+	var rs []rune
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		v, err := strconv.ParseInt(s, 10, 32)
+		if err != nil || v < 0 || v > unicode.MaxRune {
+			return nil, fmt.Errorf("line %d: invalid rune %q", line, s)
+		}
+		rs = append(rs, rune(v))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	slices.Sort(rs)
+	return slices.Compact(rs), nil
+}
+
+func exprSource(expr string) (source, error) {
+	var set runes.MinMaxSet
+	var test string
+	op := byte('+')
+	for s := expr; ; {
+		i := strings.IndexAny(s, "+-&")
+		if i < 0 {
+			i = len(s)
+		}
+		name := strings.TrimSpace(s[:i])
+		rt, goExpr, err := lookupTable(name)
+		if err != nil {
+			return source{}, fmt.Errorf("expression %q: %w", expr, err)
+		}
+		operand := runes.FromRangeTable(rt)
+		is := "unicode.Is(" + goExpr + ", r)"
+		switch {
+		case set == nil:
+			set, test = operand, is
+		case op == '+':
+			set, test = runes.UnionOf(set, operand), "("+test+" || "+is+")"
+		case op == '-':
+			set, test = runes.Difference(set, operand), "("+test+" && !"+is+")"
+		default:
+			set, test = runes.Intersection(set, operand), "("+test+" && "+is+")"
+		}
+		if i == len(s) {
+			break
+		}
+		op, s = s[i], s[i+1:]
+	}
+	return source{
+		desc:  "the runes of the expression " + strconv.Quote(expr),
+		runes: slices.Collect(set.(runes.Enumerable).All()),
+		test:  test,
+		deps:  []string{"unicode"},
+	}, nil
+}
+
+// lookupTable returns the table with the given name in package unicode, and a
+// Go expression to reference it.
+func lookupTable(name string) (*unicode.RangeTable, string, error) {
+	for _, m := range []struct {
+		name   string
+		tables map[string]*unicode.RangeTable
+	}{
+		{"Categories", unicode.Categories},
+		{"Scripts", unicode.Scripts},
+		{"Properties", unicode.Properties},
+	} {
+		if rt, ok := m.tables[name]; ok {
+			return rt, "unicode." + m.name + "[" + strconv.Quote(name) + "]", nil
+		}
+	}
+	return nil, "", fmt.Errorf("unknown unicode table %q", name)
+}
+
+func rangeTableRunes(rt *unicode.RangeTable) []rune {
+	var rs []rune
+	for _, r := range rt.R16 {
+		for v := rune(r.Lo); v <= rune(r.Hi); v += rune(r.Stride) {
+			rs = append(rs, v)
+		}
+	}
+	for _, r := range rt.R32 {
+		for v := rune(r.Lo); v <= rune(r.Hi); v += rune(r.Stride) {
+			rs = append(rs, v)
+		}
+	}
+	return rs
+}
+
+const header = "// Code generated by runesgen; DO NOT EDIT.\n\n"
+
+func (c config) code(src source, set runes.MinMaxSet) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(header)
+	fmt.Fprintf(&b, "package %s\n\n", c.pkg)
+	b.WriteString("import \"github.com/diegommm/runes\"\n\n")
+	fmt.Fprintf(&b, "// %s is the set of %s.\n", c.name, src.desc)
+	fmt.Fprintf(&b, "var %s = %#v\n", c.name, set)
+	return format.Source(b.Bytes())
+}
+
+func (c config) testCode(src source) ([]byte, error) {
+	imports := append([]string{"testing"}, src.deps...)
+	test := src.test
+	listName := c.name + "Source"
+	inList := "in" + exported(listName)
+	if src.list {
+		imports = append(imports, "slices")
+		test = inList + "(r)"
+	}
+	slices.Sort(imports)
+
+	var b bytes.Buffer
+	b.WriteString(header)
+	fmt.Fprintf(&b, "package %s\n\n", c.pkg)
+	b.WriteString("import (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&b, "%q\n", imp)
+	}
+	b.WriteString(")\n\n")
+	if src.list {
+		fmt.Fprintf(&b, "var %s = []rune{", listName)
+		for i, r := range src.runes {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%d", r)
+		}
+		b.WriteString("}\n\n")
+		fmt.Fprintf(&b, "func %s(r rune) bool {\n", inList)
+		fmt.Fprintf(&b, "_, ok := slices.BinarySearch(%s, r)\nreturn ok\n}\n\n", listName)
+	}
+	fmt.Fprintf(&b, "func Test%s(t *testing.T) {\n", exported(c.name))
+	b.WriteString("for r := rune(-1); r <= 0x10ffff+1; r++ {\n")
+	fmt.Fprintf(&b, "if got, want := %s.Contains(r), %s; got != want {\n", c.name, test)
+	fmt.Fprintf(&b, "t.Fatalf(\"%s.Contains(%%U) = %%v, want %%v\", r, got, want)\n", c.name)
+	b.WriteString("}\n}\n}\n")
+	return format.Source(b.Bytes())
+}
+
+// exported returns `name` with its first letter in upper case.
+func exported(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
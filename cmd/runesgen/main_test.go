@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestParseRunes(t *testing.T) {
+	rs, err := parseRunes(strings.NewReader("# comment\n98\n\n97\n98\n"))
+	util.MustEqual(t, nil, err, "parseRunes")
+	util.Equal(t, true, slices.Equal([]rune{97, 98}, rs), "unexpected runes: %v", rs)
+
+	for _, s := range []string{"a\n", "-1\n", "1114112\n"} {
+		_, err := parseRunes(strings.NewReader(s))
+		util.Equal(t, true, err != nil, "expected error for %q", s)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	dir := t.TempDir()
+	testCases := [][]string{
+		{"-name", "x", "-pkg", "p"},
+		{"-name", "x", "-pkg", "p", "-table", "Greek", "-expr", "Latin"},
+		{"-name", "x", "-pkg", "p", "-table", "Nope"},
+		{"-name", "x", "-pkg", "p", "-expr", "Greek-"},
+		{"-name", "x", "-pkg", "p", "-file", filepath.Join(dir, "nope")},
+		{"-name", "1x", "-pkg", "p", "-table", "Greek"},
+		{"-name", "x", "-pkg", "", "-table", "Greek"},
+		{"-name", "x", "-pkg", "p", "-table", "Greek", "extra"},
+		{"-nope"},
+	}
+
+	for i, args := range testCases {
+		err := run(args, io.Discard)
+		util.Equal(t, true, err != nil, "index=%v; expected error", i)
+	}
+}
+
+// TestGenerate generates sets from every kind of source, and runs the
+// generated tests in a temporary module.
+func TestGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := filepath.Abs("../..")
+	util.MustEqual(t, nil, err, "module root")
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+		util.MustEqual(t, nil, err, "write %s", name)
+	}
+	write("go.mod", "module example.com/gen\n\ngo 1.24.0\n\n"+
+		"require github.com/diegommm/runes v0.0.0\n\n"+
+		"replace github.com/diegommm/runes => "+root+"\n")
+	write("runes.txt", "# some runes\n9\n10\n32\n133\n12288\n")
+
+	testCases := [][]string{
+		{"-name", "greek", "-table", "Greek"},
+		{"-name", "WhiteSpace", "-table", "White_Space", "-stepcost", "0"},
+		{"-name", "fromFile", "-file", filepath.Join(dir, "runes.txt")},
+		{"-name", "nonLatinLetters", "-expr", "L - Latin & Lu", "-o", filepath.Join(dir, "expr.go")},
+	}
+	for i, args := range testCases {
+		args = append(args, "-pkg", "gen")
+		if !slices.Contains(args, "-o") {
+			args = append(args, "-o", filepath.Join(dir, args[1]+".go"))
+		}
+		err := run(args, io.Discard)
+		util.MustEqual(t, nil, err, "index=%v; run", i)
+	}
+
+	cmd := exec.Command(goBin, "test", "-mod=mod", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOPROXY=off")
+	out, err := cmd.CombinedOutput()
+	util.Equal(t, nil, err, "go test: %s", out)
+}
@@ -0,0 +1,107 @@
+package runes
+
+import "iter"
+
+// Equal returns whether `a` and `b` have the same runes. It returns early if
+// their Min or Max differ, and otherwise the sets are compared by walking their
+// ranges in lockstep, so it is cheap for sets with few ranges regardless of
+// their representation.
+func Equal(a, b MinMaxSet) bool {
+	// the bounds of a set are not necessarily members, so they only tell the
+	// sets apart if the outermost one is
+	minA, minB, maxA, maxB := a.Min(), b.Min(), a.Max(), b.Max()
+	switch {
+	case minA < minB && a.Contains(rune(minA)), minB < minA && b.Contains(rune(minB)):
+		return false
+	case minA == MaxUint32 || minB == MaxUint32:
+		// an empty set has no Max
+	case maxA > maxB && a.Contains(rune(maxA)), maxB > maxA && b.Contains(rune(maxB)):
+		return false
+	}
+
+	nextA, stopA := iter.Pull2(setRanges(a))
+	defer stopA()
+	nextB, stopB := iter.Pull2(setRanges(b))
+	defer stopB()
+	for {
+		loA, hiA, okA := nextA()
+		loB, hiB, okB := nextB()
+		if okA != okB || loA != loB || hiA != hiB {
+			return false
+		}
+		if !okA {
+			return true
+		}
+	}
+}
+
+// IsSubset returns whether all the runes of `a` are also in `b`. Each range of
+// `a` is checked to be within a range of `b`, walking both in lockstep.
+func IsSubset(a, b MinMaxSet) bool {
+	if a.Min() == MaxUint32 {
+		return true
+	}
+	nextB, stopB := iter.Pull2(setRanges(b))
+	defer stopB()
+	loB, hiB, okB := nextB()
+	for loA, hiA := range setRanges(a) {
+		for okB && hiB < loA {
+			loB, hiB, okB = nextB()
+		}
+		if !okB || loA < loB || hiA > hiB {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersects returns whether `a` and `b` have at least one rune in common. The
+// bounds of the sets are checked first. Then, if both sets are [Navigable],
+// they are leapfrogged using Next, otherwise their ranges are walked in
+// lockstep.
+func Intersects(a, b MinMaxSet) bool {
+	if a.Min() == MaxUint32 || b.Min() == MaxUint32 ||
+		a.Max() < b.Min() || b.Max() < a.Min() {
+		return false
+	}
+	navA, okA := a.(Navigable)
+	navB, okB := b.(Navigable)
+	if okA && okB {
+		return leapfrog(navA, navB, rune(max(a.Min(), b.Min())))
+	}
+
+	nextB, stopB := iter.Pull2(setRanges(b))
+	defer stopB()
+	loB, hiB, okB := nextB()
+	for loA, hiA := range setRanges(a) {
+		for okB && hiB < loA {
+			loB, hiB, okB = nextB()
+		}
+		if !okB {
+			return false
+		}
+		if loB <= hiA {
+			return true
+		}
+	}
+	return false
+}
+
+// leapfrog returns whether `a` and `b` have a rune in common that is greater
+// than or equal to `r`.
+func leapfrog(a, b Navigable, r rune) bool {
+	for {
+		ra, ok := a.Next(r)
+		if !ok {
+			return false
+		}
+		rb, ok := b.Next(ra)
+		if !ok {
+			return false
+		}
+		if ra == rb {
+			return true
+		}
+		r = rb
+	}
+}
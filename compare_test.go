@@ -0,0 +1,79 @@
+package runes
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	letterRunes := slices.Collect(util.RangeTableIter(unicode.Letter))
+	letter := FromRangeTable(unicode.Letter)
+	letterBitmap := NewBitmap(letterRunes)
+	letterLinear := Compile(letterRunes, StepCost(0))
+	lessLetter := Compile(letterRunes[1:])
+	moreLetter := UnionOf(letter, Interval[uint8]{'0', '0'})
+	latinLetter := Intersection(letter, FromRangeTable(unicode.Latin))
+	punct := FromRangeTable(unicode.Punct)
+	digit := FromRangeTable(unicode.Digit)
+	empty := LinearSlice[uint8](nil)
+	evens := minMaxFunc{
+		ContainsFunc: func(r rune) bool { return r%2 == 0 },
+		min:          0,
+		max:          10,
+	}
+
+	testCases := []struct {
+		a, b                        MinMaxSet
+		equal, isSubset, intersects bool
+	}{
+		{empty, empty, true, true, false},
+		{empty, letter, false, true, false},
+		{letter, empty, false, false, false},
+		{letter, letter, true, true, true},
+		{letter, letterBitmap, true, true, true},
+		{letterBitmap, letterLinear, true, true, true},
+		{lessLetter, letter, false, true, true},
+		{letter, lessLetter, false, false, true},
+		{moreLetter, letter, false, false, true},
+		{latinLetter, letter, false, true, true},
+		{letter, latinLetter, false, false, true},
+		{letter, punct, false, false, false},
+		{digit, letterBitmap, false, false, false},
+		{Interval[uint8]{'a', 'z'}, Uniform[uint8]{'b', 'z', 2}, false, false, true},
+		{Uniform[uint8]{'b', 'z', 2}, Interval[uint8]{'a', 'z'}, false, true, true},
+		{Uniform[uint8]{'a', 'y', 2}, Uniform[uint8]{'b', 'z', 2}, false, false, false},
+		{evens, Uniform[uint8]{0, 10, 2}, true, true, true},
+		{evens, Uniform[uint8]{1, 9, 2}, false, false, false},
+		{evens, Interval[uint8]{10, 20}, false, false, true},
+		{And(letter, punct), empty, true, true, false},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			util.Equal(t, tc.equal, Equal(tc.a, tc.b), "Equal")
+			util.Equal(t, tc.isSubset, IsSubset(tc.a, tc.b), "IsSubset")
+			util.Equal(t, tc.intersects, Intersects(tc.a, tc.b), "Intersects")
+			util.Equal(t, tc.intersects, Intersects(tc.b, tc.a), "Intersects (reversed)")
+		})
+	}
+}
+
+func TestEqualBounds(t *testing.T) {
+	t.Parallel()
+	var calls int
+	all := minMaxFunc{
+		ContainsFunc: func(rune) bool { calls++; return true },
+		min:          0,
+		max:          utf8.MaxRune,
+	}
+	util.Equal(t, false, Equal(all, Interval[uint8]{1, 5}), "Equal by Min")
+	util.Equal(t, false, Equal(Interval[rune]{0, 5}, all), "Equal by Max")
+	util.Equal(t, 2, calls, "Contains calls")
+}
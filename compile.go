@@ -0,0 +1,491 @@
+package runes
+
+import (
+	"math/bits"
+	"slices"
+	"unicode/utf8"
+)
+
+// Option configures [Compile] and [NewMixedUnion].
+type Option func(*compileConfig)
+
+type compileConfig struct {
+	stepCost int
+}
+
+// defaultStepCost is the default value of [StepCost].
+const defaultStepCost = 8
+
+// StepCost sets how many bytes a single lookup step is worth in the cost model
+// of [Compile]. Higher values favour faster sets, lower values favour smaller
+// sets. The default is 8. Negative values are treated as zero.
+func StepCost(bytes int) Option {
+	return func(c *compileConfig) {
+		c.stepCost = max(bytes, 0)
+	}
+}
+
+// Estimated sizes in bytes of the headers of the types used by Compile.
+const (
+	sliceHdrSize  = 24
+	stringHdrSize = 16
+	ifaceSize     = 16
+)
+
+// maxSegmentAtoms is the maximum number of atoms Compile will try to group in
+// a single segment, which bounds the cost of the search to O(n*k).
+const maxSegmentAtoms = 128
+
+// Compile returns a [MinMaxSet] containing the given runes, choosing the
+// representation with the lowest estimated cost. The runes need not be sorted,
+// and both duplicates and values outside [0, utf8.MaxRune] are ignored.
+//
+// The cost of a set is the estimated number of bytes it takes plus [StepCost]
+// times the estimated number of steps of a worst-case lookup. The runes are
+// first split into atoms, which are maximal runs of equally spaced runes.
+// Consecutive atoms are then grouped into segments, each of which is
+// represented with the cheapest of [Interval] or [Uniform] (for single atoms),
+// [LinearSlice], [BinarySlice] and [StridedRanges] (for many atoms), [Bitmap]
+// and [SparseBitmap], always using the narrowest [RuneT] that can hold it.
+// Multiple segments are joined in a [SortedUnion], where each member adds the
+// size of an interface value, of its bounds and of its rank, and one step, to
+// the sum of the costs of the members. The grouping with the lowest total cost
+// is chosen. If the segments are all intervals, all uniforms or all bitmaps,
+// the SortedUnion has members of that concrete type. Otherwise, a [MixedUnion]
+// is used instead if it is cheaper, even though it stores runes as uint32,
+// splits strided ranges in a segment per range and sparse bitmaps in a bitmap
+// per run of consecutive blocks.
+func Compile(rs []rune, opts ...Option) MinMaxSet {
+	return newCompileConfig(opts).compile(splitAtoms(normalizeRunes(rs)))
+}
+
+func newCompileConfig(opts []Option) compileConfig {
+	cfg := compileConfig{stepCost: defaultStepCost}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return cfg
+}
+
+// compile returns the set with the lowest cost for the given sorted atoms.
+func (c compileConfig) compile(atoms []atom) MinMaxSet {
+	return c.joinSegments(c.segment(atoms))
+}
+
+// joinSegments returns the set represented by the given segments.
+func (c compileConfig) joinSegments(segs []segment) MinMaxSet {
+	switch len(segs) {
+	case 0:
+		return LinearSlice[uint8](nil)
+	case 1:
+		return segs[0].set()
+	default:
+		return c.newUnion(segs)
+	}
+}
+
+// normalizeRunes returns a sorted copy of `rs` without duplicates or invalid
+// runes.
+func normalizeRunes(rs []rune) []rune {
+	res := make([]rune, 0, len(rs))
+	for _, r := range rs {
+		if r >= 0 && r <= utf8.MaxRune {
+			res = append(res, r)
+		}
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+// atom is a run of equally spaced runes.
+type atom struct {
+	lo, hi, stride rune
+	n              int // number of runes
+}
+
+// splitAtoms splits sorted unique runes into atoms, favouring runs of
+// consecutive runes over runs with bigger strides.
+func splitAtoms(rs []rune) []atom {
+	var atoms []atom
+	for i := 0; i < len(rs); {
+		j := i
+		if i+1 < len(rs) {
+			d := rs[i+1] - rs[i]
+			for j = i + 1; j+1 < len(rs) && rs[j+1]-rs[j] == d; j++ {
+			}
+			if d > 1 && j+1 < len(rs) && rs[j+1]-rs[j] == 1 {
+				j-- // let the last rune start a run of consecutive runes
+			}
+		}
+		a := atom{lo: rs[i], hi: rs[j], stride: 1, n: j - i + 1}
+		if a.n > 1 {
+			a.stride = rs[i+1] - rs[i]
+		}
+		atoms = append(atoms, a)
+		i = j + 1
+	}
+	return atoms
+}
+
+type segKind uint8
+
+const (
+	segInterval segKind = iota
+	segUniform
+	segBitmap
+	segLinear
+	segBinary
+	segStrided
+	segSparse
+)
+
+// segment is a group of consecutive atoms and the representation chosen for
+// it.
+type segment struct {
+	kind           segKind
+	lo, hi, stride rune
+	atoms          []atom
+	cost           int
+}
+
+// runes returns the runes of the segment.
+func (s segment) runes() []rune {
+	var n int
+	for _, a := range s.atoms {
+		n += a.n
+	}
+	rs := make([]rune, 0, n)
+	for _, a := range s.atoms {
+		for r := a.lo; r <= a.hi; r += a.stride {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// segment groups the atoms in segments with the lowest total cost.
+func (c compileConfig) segment(atoms []atom) []segment {
+	if len(atoms) == 0 {
+		return nil
+	}
+	// offsets[k] is the number of runes in atoms[:k], and blocks[k] is the
+	// number of distinct blocks of 64 runes they take
+	offsets := make([]int, len(atoms)+1)
+	blocks := make([]int, len(atoms)+1)
+	shared := func(k int) bool {
+		return k > 0 && atoms[k].lo>>blockBits == atoms[k-1].hi>>blockBits
+	}
+	for k, a := range atoms {
+		offsets[k+1] = offsets[k] + a.n
+		n := a.n
+		if a.stride <= 1<<blockBits {
+			// no block is skipped between the first and the last rune
+			n = int(a.hi>>blockBits-a.lo>>blockBits) + 1
+		}
+		if shared(k) {
+			n--
+		}
+		blocks[k+1] = blocks[k] + n
+	}
+	seg := func(i, j int) segment {
+		n := blocks[j+1] - blocks[i]
+		if shared(i) {
+			n++ // the first block is shared with the previous atom
+		}
+		return c.cheapest(atoms[i:j+1], offsets[j+1]-offsets[i], n)
+	}
+
+	// best[j] is the lowest cost to represent atoms[:j], and from[j] is the
+	// index of the first atom of the last segment in that case
+	memberCost := c.memberCost()
+	best := make([]int, len(atoms)+1)
+	from := make([]int, len(atoms)+1)
+	for j := range atoms {
+		best[j+1] = -1
+		for i := j; i >= 0 && j-i < maxSegmentAtoms; i-- {
+			cost := best[i] + seg(i, j).cost + memberCost
+			if best[j+1] < 0 || cost < best[j+1] {
+				best[j+1], from[j+1] = cost, i
+			}
+		}
+	}
+
+	var segs []segment
+	for j := len(atoms); j > 0; j = from[j] {
+		segs = append(segs, seg(from[j], j-1))
+	}
+	slices.Reverse(segs)
+
+	// grouping everything in a single segment is not bounded by
+	// maxSegmentAtoms, and saves the cost of the SortedUnion
+	if len(segs) > 1 {
+		whole := seg(0, len(atoms)-1)
+		if whole.cost <= best[len(atoms)]+3*sliceHdrSize {
+			return []segment{whole}
+		}
+	}
+	return segs
+}
+
+// memberCost is the cost that each member adds to a SortedUnion: the size of
+// an interface value, of its bounds and of its rank, and one step.
+func (c compileConfig) memberCost() int {
+	return ifaceSize + 3*4 + c.stepCost
+}
+
+// cheapest returns the segment with the lowest cost to represent the given
+// atoms, which hold `n` runes in `blocks` distinct blocks of 64 runes.
+func (c compileConfig) cheapest(atoms []atom, n, blocks int) segment {
+	lo, hi := atoms[0].lo, atoms[len(atoms)-1].hi
+	w := runeWidth(hi)
+	candidate := func(kind segKind, bytes, steps int) segment {
+		return segment{
+			kind:  kind,
+			lo:    lo,
+			hi:    hi,
+			atoms: atoms,
+			cost:  bytes + c.stepCost*steps,
+		}
+	}
+
+	var res segment
+	if len(atoms) == 1 && atoms[0].stride == 1 {
+		res = candidate(segInterval, 2*w, 1)
+	} else if len(atoms) == 1 {
+		res = candidate(segUniform, 3*w, 2)
+		res.stride = atoms[0].stride
+	} else {
+		res = candidate(segBinary, sliceHdrSize+n*w, 1+2*bits.Len(uint(n)))
+		if lin := candidate(segLinear, sliceHdrSize+n*w, 1+n); lin.cost <= res.cost {
+			res = lin
+		}
+		// a binary search over the atoms, and a division
+		strided := candidate(segStrided, sliceHdrSize+len(atoms)*3*w, 2+2*bits.Len(uint(len(atoms))))
+		if strided.cost < res.cost {
+			res = strided
+		}
+	}
+	bmBytes := stringHdrSize + int(bmHdrLen+ceilDiv(uint32(hi-lo+1), 8))
+	if bm := candidate(segBitmap, bmBytes, 2); bm.cost < res.cost {
+		res = bm
+	}
+	// a binary search over the blocks, and a lookup of the bit in the word
+	sparse := candidate(segSparse, 3*sliceHdrSize+blocks*(2+8+4)+4, 2+2*bits.Len(uint(blocks)))
+	if sparse.cost < res.cost {
+		res = sparse
+	}
+	return res
+}
+
+// set materializes the segment using the narrowest possible RuneT.
+func (s segment) set() MinMaxSet {
+	switch s.kind {
+	case segInterval:
+		return narrowest(s.hi, newInterval[uint8], newInterval[uint16], newInterval[rune])(s.lo, s.hi)
+	case segUniform:
+		return narrowest(s.hi, newUniform[uint8], newUniform[uint16], newUniform[rune])(s.lo, s.hi, s.stride)
+	case segBitmap:
+		return NewBitmap(s.runes())
+	case segSparse:
+		return NewSparseBitmap(s.runes())
+	case segStrided:
+		return narrowest(s.hi, newStrided[uint8], newStrided[uint16], newStrided[rune])(s.atoms)
+	default:
+		return newNarrowSlice(s.kind == segLinear, s.runes())
+	}
+}
+
+// newUnion returns the union of the given segments. If all the segments are
+// intervals, all uniforms or all bitmaps, then a SortedUnion of that concrete
+// type is returned. Otherwise, a MixedUnion is returned if it is cheaper than a
+// SortedUnion of MinMaxSet, which is the union that the segments were priced
+// for.
+func (c compileConfig) newUnion(segs []segment) MinMaxSet {
+	homogeneous := true
+	for _, s := range segs {
+		homogeneous = homogeneous && s.kind == segs[0].kind
+	}
+	hi := segs[len(segs)-1].hi
+	switch kind := segs[0].kind; {
+	case !homogeneous:
+	case kind == segInterval:
+		return narrowest(hi, intervalUnion[uint8], intervalUnion[uint16], intervalUnion[rune])(segs)
+	case kind == segUniform:
+		return narrowest(hi, uniformUnion[uint8], uniformUnion[uint16], uniformUnion[rune])(segs)
+	case kind == segBitmap:
+		u := make([]Bitmap, len(segs))
+		for i, s := range segs {
+			u[i] = NewBitmap(s.runes())
+		}
+		return newSortedUnion(u)
+	}
+
+	sortedCost := 3 * sliceHdrSize
+	for _, s := range segs {
+		sortedCost += s.cost + c.memberCost()
+	}
+	if x := newMixedUnion(segs); c.mixedCost(x) < sortedCost {
+		return x
+	}
+	u := make([]MinMaxSet, len(segs))
+	for i, s := range segs {
+		u[i] = s.set()
+	}
+	return newSortedUnion(u)
+}
+
+// mixedCost returns the cost of a MixedUnion, priced like the SortedUnion of
+// its segments: each of them adds the size of its kind, bounds and argument,
+// and one step, to its own size and steps.
+func (c compileConfig) mixedCost(x MixedUnion) int {
+	bytes := 6*sliceHdrSize + len(x.Kinds) + 4*(len(x.Bounds)+len(x.Args)+len(x.Runes)+len(x.Ranks)) +
+		8*len(x.Words)
+	steps := 0
+	for i, kind := range x.Kinds {
+		steps++
+		switch kind {
+		case MixedInterval:
+			steps++
+		case MixedStrided, MixedBitmap:
+			steps += 2
+		case MixedSlice:
+			// the binary search is bounded like in MixedUnion.Contains
+			n := min(int(x.Bounds[2*i+1]-x.Bounds[2*i])+1, len(x.Runes)-int(x.Args[i]))
+			steps += 1 + 2*bits.Len(uint(n))
+		}
+	}
+	return bytes + c.stepCost*steps
+}
+
+// newMixedUnion returns a MixedUnion with the given segments. Strided ranges
+// are split in their atoms, and sparse bitmaps in bitmaps of consecutive
+// blocks, since a MixedUnion has no segments of those kinds.
+func newMixedUnion(segs []segment) MixedUnion {
+	var x MixedUnion
+	for _, s := range segs {
+		switch s.kind {
+		case segInterval:
+			x.appendSegment(MixedInterval, s.lo, s.hi, 0)
+		case segUniform:
+			x.appendSegment(MixedStrided, s.lo, s.hi, uint32(s.stride))
+		case segStrided:
+			for _, a := range s.atoms {
+				if a.stride == 1 {
+					x.appendSegment(MixedInterval, a.lo, a.hi, 0)
+				} else {
+					x.appendSegment(MixedStrided, a.lo, a.hi, uint32(a.stride))
+				}
+			}
+		case segLinear, segBinary:
+			x.appendSegment(MixedSlice, s.lo, s.hi, uint32(len(x.Runes)))
+			for _, r := range s.runes() {
+				x.Runes = append(x.Runes, uint32(r))
+			}
+		case segBitmap:
+			x.appendBitmap(s.runes())
+		case segSparse:
+			rs := s.runes()
+			for i := 0; i < len(rs); {
+				j := i + 1
+				for j < len(rs) && rs[j]>>blockBits-rs[j-1]>>blockBits <= 1 {
+					j++
+				}
+				x.appendBitmap(rs[i:j])
+				i = j
+			}
+		}
+	}
+	x.Ranks = x.ranks()
+	return x
+}
+
+func (x *MixedUnion) appendSegment(kind MixedKind, lo, hi rune, arg uint32) {
+	x.Kinds = append(x.Kinds, kind)
+	x.Bounds = append(x.Bounds, uint32(lo), uint32(hi))
+	x.Args = append(x.Args, arg)
+}
+
+// appendBitmap appends a MixedBitmap segment with the given sorted runes.
+func (x *MixedUnion) appendBitmap(rs []rune) {
+	lo, hi := rs[0], rs[len(rs)-1]
+	x.appendSegment(MixedBitmap, lo, hi, uint32(len(x.Words)))
+	words := make([]uint64, (hi-lo)>>blockBits+1)
+	for _, r := range rs {
+		words[(r-lo)>>blockBits] |= 1 << ((r - lo) & blockMask)
+	}
+	x.Words = append(x.Words, words...)
+}
+
+func intervalUnion[T RuneT](segs []segment) MinMaxSet {
+	u := make([]Interval[T], len(segs))
+	for i, s := range segs {
+		u[i] = Interval[T]{T(s.lo), T(s.hi)}
+	}
+	return newSortedUnion(u)
+}
+
+func uniformUnion[T RuneT](segs []segment) MinMaxSet {
+	u := make([]Uniform[T], len(segs))
+	for i, s := range segs {
+		u[i] = Uniform[T]{T(s.lo), T(s.hi), T(s.stride)}
+	}
+	return newSortedUnion(u)
+}
+
+func newInterval[T RuneT](lo, hi rune) MinMaxSet {
+	return Interval[T]{T(lo), T(hi)}
+}
+
+func newUniform[T RuneT](lo, hi, stride rune) MinMaxSet {
+	return Uniform[T]{T(lo), T(hi), T(stride)}
+}
+
+func newStrided[T RuneT](atoms []atom) MinMaxSet {
+	return newStridedRanges[T](atoms)
+}
+
+// newNarrowSlice returns a LinearSlice or a BinarySlice with the given sorted
+// runes, using the narrowest RuneT that can hold them.
+func newNarrowSlice(linear bool, rs []rune) MinMaxSet {
+	var hi rune
+	if len(rs) > 0 {
+		hi = rs[len(rs)-1]
+	}
+	return narrowest(hi, newSlice[uint8], newSlice[uint16], newSlice[rune])(linear, rs)
+}
+
+func newSlice[T RuneT](linear bool, rs []rune) MinMaxSet {
+	s := make([]T, len(rs))
+	for i := range rs {
+		s[i] = T(rs[i])
+	}
+	if linear {
+		return LinearSlice[T](s)
+	}
+	return BinarySlice[T](s)
+}
+
+// narrowest returns the function for the narrowest RuneT that can hold `hi`.
+func narrowest[F any](hi rune, f8, f16, f32 F) F {
+	switch runeWidth(hi) {
+	case 1:
+		return f8
+	case 2:
+		return f16
+	default:
+		return f32
+	}
+}
+
+// runeWidth returns the size in bytes of the narrowest RuneT that can hold
+// `r`.
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0 && r <= maxUint8:
+		return 1
+	case r >= 0 && r <= maxUint16:
+		return 2
+	default:
+		return 4
+	}
+}
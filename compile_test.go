@@ -0,0 +1,128 @@
+package runes
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+	tables := []*unicode.RangeTable{
+		unicode.White_Space,
+		unicode.Greek,
+		unicode.Upper,
+		unicode.Letter,
+		unicode.Han,
+		unicode.Noncharacter_Code_Point,
+		unicode.Po,
+	}
+	var tcs setTestCases
+	for _, rt := range tables {
+		rs := slices.Collect(util.RangeTableIter(rt))
+		for _, stepCost := range []int{0, defaultStepCost, 1000} {
+			tcs = append(tcs, setTestCase{
+				set:         Compile(rs, StepCost(stepCost)),
+				contains:    runes(rs...),
+				notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), runes(rs...)),
+			})
+		}
+	}
+	tcs = append(tcs,
+		setTestCase{
+			set:         Compile(nil),
+			notContains: util.Seq(-1, utf8.MaxRune, 1),
+		},
+		setTestCase{
+			set:         Compile([]rune{-1, 5, utf8.MaxRune + 1, 3, 5}),
+			contains:    runes(3, 5),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), runes(3, 5)),
+		},
+	)
+	tcs.run(t)
+}
+
+func TestCompileRepresentation(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		rs       []rune
+		expected MinMaxSet
+	}{
+		{nil, LinearSlice[uint8](nil)},
+		{[]rune{'a'}, Interval[uint8]{'a', 'a'}},
+		{slices.Collect(util.Seq('a', 'z', 1)), Interval[uint8]{'a', 'z'}},
+		{slices.Collect(util.Seq(0x100, 0x200, 1)), Interval[uint16]{0x100, 0x200}},
+		{slices.Collect(util.Seq(0x10000, 0x10010, 1)), Interval[rune]{0x10000, 0x10010}},
+		{slices.Collect(util.Seq(0x100, 0x200, 2)), Uniform[uint16]{0x100, 0x200, 2}},
+		{
+			rs: slices.Collect(util.Concat(
+				util.Seq('0', '9', 1),
+				util.Seq('a', 'z', 1),
+				util.Seq(0x4e00, 0x9fff, 1),
+			)),
+			expected: StridedRanges[uint16]{{'0', '9', 1}, {'a', 'z', 1}, {0x4e00, 0x9fff, 1}},
+		},
+	}
+
+	for i, tc := range testCases {
+		got := Compile(tc.rs)
+		util.Equal(t, fmt.Sprintf("%#v", tc.expected), fmt.Sprintf("%#v", got), "index=%v", i)
+	}
+}
+
+func TestCompileStepCost(t *testing.T) {
+	t.Parallel()
+	rs := slices.Collect(util.RangeTableIter(unicode.White_Space))
+
+	_, isStrided := Compile(rs, StepCost(0)).(StridedRanges[uint16])
+	util.Equal(t, true, isStrided, "size only cost model should produce StridedRanges")
+
+	_, isBitmap := Compile(rs, StepCost(1000)).(Bitmap)
+	util.Equal(t, true, isBitmap, "speed only cost model should produce a Bitmap")
+
+	// scattered runes with no pattern are cheaper in a SparseBitmap
+	_, isSparse := Compile(slices.Collect(util.RangeTableIter(unicode.Mn))).(SparseBitmap)
+	util.Equal(t, true, isSparse, "scattered runes should produce a SparseBitmap")
+
+	// long ranges between dense runs of scattered runes
+	var mixed []rune
+	for base := rune(0x10000); base < 0x90000; base += 0x10000 {
+		mixed = slices.AppendSeq(mixed, util.Seq(base, base+0x1fff, 1))
+		for d := rune(0x3000); d < 0x3080; d++ {
+			if d*d%7 < 3 {
+				mixed = append(mixed, base+d)
+			}
+		}
+	}
+	_, isMixed := Compile(mixed).(MixedUnion)
+	util.Equal(t, true, isMixed, "mixed density runes should produce a MixedUnion")
+
+	// splitting the strided ranges and sparse bitmaps of letters makes a
+	// MixedUnion bigger than a SortedUnion
+	_, isSorted := Compile(slices.Collect(util.RangeTableIter(unicode.Letter))).(SortedUnion[MinMaxSet])
+	util.Equal(t, true, isSorted, "letters should produce a SortedUnion")
+}
+
+func TestSplitAtoms(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		rs       []rune
+		expected []atom
+	}{
+		{nil, nil},
+		{[]rune{1}, []atom{{1, 1, 1, 1}}},
+		{[]rune{1, 2, 3}, []atom{{1, 3, 1, 3}}},
+		{[]rune{1, 3, 5}, []atom{{1, 5, 2, 3}}},
+		{[]rune{1, 3, 5, 6, 7}, []atom{{1, 3, 2, 2}, {5, 7, 1, 3}}},
+		{[]rune{1, 2, 10, 20}, []atom{{1, 2, 1, 2}, {10, 20, 10, 2}}},
+	}
+
+	for i, tc := range testCases {
+		got := splitAtoms(tc.rs)
+		util.Equal(t, true, slices.Equal(tc.expected, got), "index=%v; got: %v", i, got)
+	}
+}
@@ -0,0 +1,202 @@
+package runes
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"slices"
+	"sync"
+	"unsafe"
+)
+
+// A container holds many named sets in a single buffer. It starts with
+// containerMagic and a version byte, followed by the uvarint number of sets
+// and a directory with the uvarint length of the name, the name and the
+// uvarint length of the encoded set for each of them, sorted by name. After the
+// directory come the sets encoded with MarshalBinary, in the same order.
+const containerMagic = "RUNES"
+
+// containerVersion is the version of the container format.
+const containerVersion = 1
+
+// WriteContainer writes the given sets to `w` in a container that can be read
+// with [OpenContainer] and its variants.
+func WriteContainer(w io.Writer, sets map[string]MinMaxSet) error {
+	names := slices.Sorted(maps.Keys(sets))
+	blobs := make([][]byte, len(names))
+	dir := binary.AppendUvarint(append([]byte(containerMagic), containerVersion), uint64(len(names)))
+	for i, name := range names {
+		var err error
+		blobs[i], err = appendValue([]byte{binaryVersion}, sets[name])
+		if err != nil {
+			return fmt.Errorf("encode set %q: %w", name, err)
+		}
+		dir = binary.AppendUvarint(dir, uint64(len(name)))
+		dir = append(dir, name...)
+		dir = binary.AppendUvarint(dir, uint64(len(blobs[i])))
+	}
+	if _, err := w.Write(dir); err != nil {
+		return err
+	}
+	for _, b := range blobs {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Container is a read-only collection of named sets. Each set is decoded the
+// first time it is retrieved, and later calls return the same value.
+//
+// Bitmaps and slices of uint8, including those in unions, reference the data
+// of the container instead of copying it, so the data must not be modified
+// while they are in use. Modifying those sets is not allowed either: their
+// memory may be read-only, like that of a go:embed string or of a file mapped
+// by [OpenContainerFile], in which case a write crashes the program. The
+// elements of other slices are always copied, since their encoding is not
+// aligned nor laid out like their Go values.
+type Container struct {
+	data  []byte
+	names []string
+	sets  map[string]func() (MinMaxSet, error)
+	close func() error
+}
+
+// OpenContainer returns the [Container] stored in `data`. The directory of the
+// container is validated, while each set is validated when it is retrieved.
+func OpenContainer(data []byte) (*Container, error) {
+	d := decoder{b: data}
+	if len(d.b) < len(containerMagic)+1 || string(d.b[:len(containerMagic)]) != containerMagic {
+		return nil, d.errorf("not a container")
+	}
+	if v := d.b[len(containerMagic)]; v != containerVersion {
+		return nil, d.errorf("unsupported container version %d", v)
+	}
+	d.b = d.b[len(containerMagic)+1:]
+
+	// each entry takes at least 2 bytes
+	n, err := d.length(2)
+	if err != nil {
+		return nil, err
+	}
+	c := &Container{
+		data:  data,
+		names: make([]string, n),
+		sets:  make(map[string]func() (MinMaxSet, error), n),
+	}
+	sizes := make([]int, n)
+	for i := range n {
+		l, err := d.length(1)
+		if err != nil {
+			return nil, err
+		}
+		c.names[i] = string(d.b[:l])
+		d.b = d.b[l:]
+		if sizes[i], err = d.length(1); err != nil {
+			return nil, err
+		}
+	}
+	for i, name := range c.names {
+		if len(d.b) < sizes[i] {
+			return nil, d.errorf("set %q exceeds data", name)
+		}
+		c.sets[name] = decodeOnce(name, d.b[:sizes[i]:sizes[i]])
+		d.b = d.b[sizes[i]:]
+	}
+	if len(c.sets) != len(c.names) {
+		return nil, d.errorf("duplicated set names")
+	}
+	return c, nil
+}
+
+// OpenContainerString is like [OpenContainer], but takes a string. It is
+// meant to be used with a string variable initialized with a go:embed
+// directive, in which case the container is not copied to the heap.
+func OpenContainerString(data string) (*Container, error) {
+	return OpenContainer(unsafe.Slice(unsafe.StringData(data), len(data)))
+}
+
+// OpenContainerFS reads a container from a file of `fsys`, like an embed.FS.
+// The contents of the file are read once, and the sets reference that copy.
+func OpenContainerFS(fsys fs.FS, name string) (*Container, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return OpenContainer(data)
+}
+
+// OpenContainerReaderAt reads a container of the given size from `r`. The
+// contents are read once, and the sets reference that copy. It fails with
+// [io.ErrUnexpectedEOF] if `r` holds fewer than `size` bytes.
+func OpenContainerReaderAt(r io.ReaderAt, size int64) (*Container, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("runes: negative container size %d", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(io.NewSectionReader(r, 0, size), data); err != nil {
+		return nil, err
+	}
+	return OpenContainer(data)
+}
+
+// OpenContainerFile opens the container in the given file. Where supported,
+// the file is memory-mapped, so that the file is not copied to the heap, though
+// sets other than Bitmaps and slices of uint8 are still decoded into it. The
+// [Container] must be closed when done, after which none of its sets can be
+// used.
+func OpenContainerFile(path string) (*Container, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := OpenContainer(data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	c.close = unmap
+	return c, nil
+}
+
+// Close releases the resources of the container. Sets retrieved from a
+// container opened with [OpenContainerFile] must not be used after closing it.
+func (c *Container) Close() error {
+	if c.close == nil {
+		return nil
+	}
+	err := c.close()
+	c.close = nil
+	return err
+}
+
+// Names returns the names of the sets in the container, sorted.
+func (c *Container) Names() []string {
+	return slices.Clone(c.names)
+}
+
+// Set returns the set with the given name. The error wraps fs.ErrNotExist if
+// there is no such set, and [ErrInvalidEncoding] if it is malformed. It is safe
+// for concurrent use.
+func (c *Container) Set(name string) (MinMaxSet, error) {
+	set, ok := c.sets[name]
+	if !ok {
+		return nil, fmt.Errorf("runes: set %q: %w", name, fs.ErrNotExist)
+	}
+	return set()
+}
+
+// decodeOnce returns a function that decodes the set `name` from `data` the
+// first time it is called, and returns the same result afterwards.
+func decodeOnce(name string, data []byte) func() (MinMaxSet, error) {
+	return sync.OnceValues(func() (MinMaxSet, error) {
+		s, err := decode(decoder{b: data, noCopy: true})
+		if err != nil {
+			return nil, fmt.Errorf("set %q: %w", name, err)
+		}
+		return s, nil
+	})
+}
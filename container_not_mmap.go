@@ -0,0 +1,15 @@
+//go:build !unix
+
+package runes
+
+import "os"
+
+// mapFile reads the given file, and returns a no-op function. This is the
+// counterpart of the function in a file with the opposite build tag.
+func mapFile(path string) ([]byte, func() error, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
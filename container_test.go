@@ -0,0 +1,144 @@
+package runes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"unicode"
+	"unsafe"
+
+	"github.com/diegommm/runes/util"
+)
+
+func testContainerSets() map[string]MinMaxSet {
+	return map[string]MinMaxSet{
+		"Greek":       FromRangeTable(unicode.Greek),
+		"Letter":      FromRangeTable(unicode.Letter),
+		"White_Space": NewBitmap(slices.Collect(util.RangeTableIter(unicode.White_Space))),
+		"Vowels":      LinearSlice[uint8]{'a', 'e', 'i', 'o', 'u'},
+		"Empty":       Union[MinMaxSet]{},
+	}
+}
+
+func TestContainer(t *testing.T) {
+	t.Parallel()
+	sets := testContainerSets()
+	var buf bytes.Buffer
+	util.MustEqual(t, nil, WriteContainer(&buf, sets), "WriteContainer")
+	data := buf.Bytes()
+
+	path := filepath.Join(t.TempDir(), "sets.bin")
+	util.MustEqual(t, nil, os.WriteFile(path, data, 0o644), "write file")
+
+	open := map[string]func() (*Container, error){
+		"bytes": func() (*Container, error) {
+			return OpenContainer(data)
+		},
+		"string": func() (*Container, error) {
+			return OpenContainerString(string(data))
+		},
+		"fs": func() (*Container, error) {
+			return OpenContainerFS(fstest.MapFS{"sets.bin": {Data: data}}, "sets.bin")
+		},
+		"readerAt": func() (*Container, error) {
+			return OpenContainerReaderAt(bytes.NewReader(data), int64(len(data)))
+		},
+		"file": func() (*Container, error) {
+			return OpenContainerFile(path)
+		},
+	}
+
+	for name, f := range open {
+		t.Run(name, func(t *testing.T) {
+			c, err := f()
+			util.MustEqual(t, nil, err, "open")
+			defer func() {
+				util.Equal(t, nil, c.Close(), "Close")
+			}()
+
+			util.Equal(t, fmt.Sprint([]string{"Empty", "Greek", "Letter", "Vowels", "White_Space"}),
+				fmt.Sprint(c.Names()), "Names")
+			for name, expected := range sets {
+				got, err := c.Set(name)
+				util.MustEqual(t, nil, err, "Set(%q)", name)
+				util.Equal(t, true, Equal(expected, got), "Set(%q): sets differ", name)
+			}
+
+			_, err = c.Set("Nope")
+			util.Equal(t, true, errors.Is(err, fs.ErrNotExist), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestContainerNoCopy(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	util.MustEqual(t, nil, WriteContainer(&buf, testContainerSets()), "WriteContainer")
+	data := buf.Bytes()
+	c, err := OpenContainer(data)
+	util.MustEqual(t, nil, err, "OpenContainer")
+
+	inData := func(p unsafe.Pointer) bool {
+		start := uintptr(unsafe.Pointer(&data[0]))
+		return uintptr(p) >= start && uintptr(p) < start+uintptr(len(data))
+	}
+	ws, err := c.Set("White_Space")
+	util.MustEqual(t, nil, err, "Set")
+	util.Equal(t, true, inData(unsafe.Pointer(unsafe.StringData(string(ws.(Bitmap))))), "Bitmap was copied")
+	vowels, err := c.Set("Vowels")
+	util.MustEqual(t, nil, err, "Set")
+	util.Equal(t, true, inData(unsafe.Pointer(&vowels.(LinearSlice[uint8])[0])), "slice was copied")
+}
+
+func TestContainerCache(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	util.MustEqual(t, nil, WriteContainer(&buf, testContainerSets()), "WriteContainer")
+	c, err := OpenContainer(buf.Bytes())
+	util.MustEqual(t, nil, err, "OpenContainer")
+
+	// SparseBitmap copies its words, so only a cached set shares them
+	first, err := c.Set("Greek")
+	util.MustEqual(t, nil, err, "Set")
+	second, err := c.Set("Greek")
+	util.MustEqual(t, nil, err, "Set")
+	util.Equal(t, &first.(SparseBitmap).Words[0], &second.(SparseBitmap).Words[0], "Set decoded twice")
+}
+
+func TestContainerInvalid(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	util.MustEqual(t, nil, WriteContainer(&buf, testContainerSets()), "WriteContainer")
+	valid := buf.Bytes()
+
+	testCases := [][]byte{
+		nil,
+		[]byte("RUNE"),
+		[]byte("NOTRUNES\x01\x00"),
+		[]byte(containerMagic + "\x02\x00"),
+		[]byte(containerMagic + "\x01\x05"),
+		[]byte(containerMagic + "\x01\x02\x01a\x00\x01a\x00"),
+		valid[:len(valid)-1],
+	}
+
+	for i, data := range testCases {
+		_, err := OpenContainer(data)
+		util.Equal(t, true, errors.Is(err, ErrInvalidEncoding), "index=%v; unexpected error: %v", i, err)
+	}
+
+	r := bytes.NewReader(valid[:len(valid)-1])
+	_, err := OpenContainerReaderAt(r, int64(len(valid)))
+	util.Equal(t, io.ErrUnexpectedEOF, err, "OpenContainerReaderAt truncated")
+	_, err = OpenContainerReaderAt(r, -1)
+	util.Equal(t, true, err != nil, "OpenContainerReaderAt negative size")
+
+	err = WriteContainer(new(bytes.Buffer), map[string]MinMaxSet{"x": minMaxFunc{}})
+	util.Equal(t, true, errors.Is(err, ErrUnsupportedSet), "unexpected error: %v", err)
+}
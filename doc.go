@@ -0,0 +1,12 @@
+// Package runes provides sets of runes with several representations, like
+// sorted slices, ranges, bitmaps and unions of them, along with the algebra,
+// navigation, formatting and binary encoding of those sets.
+//
+// Sets encoded with MarshalBinary can be grouped in a [Container], which can
+// be loaded from memory, an embed.FS, an [io.ReaderAt] or a memory-mapped
+// file. Only the bytes of Bitmaps and of slices of uint8 are referenced
+// without copying them; every other set is decoded into new memory the first
+// time it is retrieved, because its encoding is neither aligned nor laid out
+// like its Go value. Containers save the most heap when most of their sets
+// are Bitmaps.
+package runes
@@ -0,0 +1,397 @@
+package runes
+
+import (
+	"cmp"
+	"iter"
+	"math"
+	"math/bits"
+	"slices"
+	"unicode/utf8"
+)
+
+func (x Union[T]) All() iter.Seq[rune] {
+	return rangesRunes(x.Ranges())
+}
+
+func (x Union[T]) Ranges() iter.Seq2[rune, rune] {
+	if x.sorted() {
+		return mergeRanges(func(yield func(rune, rune) bool) {
+			for i := range x {
+				for lo, hi := range setRanges(x[i]) {
+					if !yield(lo, hi) {
+						return
+					}
+				}
+			}
+		})
+	}
+	var rs [][2]rune
+	for i := range x {
+		for lo, hi := range setRanges(x[i]) {
+			rs = append(rs, [2]rune{lo, hi})
+		}
+	}
+	slices.SortFunc(rs, func(a, b [2]rune) int {
+		return cmp.Compare(a[0], b[0])
+	})
+	return mergeRanges(func(yield func(rune, rune) bool) {
+		for _, r := range rs {
+			if !yield(r[0], r[1]) {
+				return
+			}
+		}
+	})
+}
+
+// sorted returns whether each member only has runes smaller than those of the
+// next member.
+func (x Union[T]) sorted() bool {
+	for i := 1; i < len(x); i++ {
+		if x[i-1].Max() >= x[i].Min() {
+			return false
+		}
+	}
+	return true
+}
+
+func (x SortedUnion[T]) All() iter.Seq[rune] {
+	return rangesRunes(x.Ranges())
+}
+
+func (x SortedUnion[T]) Ranges() iter.Seq2[rune, rune] {
+	// members may be adjacent, so they need merging
+	return mergeRanges(func(yield func(rune, rune) bool) {
+		for _, m := range x.elems {
+			for lo, hi := range setRanges(m) {
+				if !yield(lo, hi) {
+					return
+				}
+			}
+		}
+	})
+}
+
+func (x LinearSlice[T]) All() iter.Seq[rune] {
+	return sliceRunes(x)
+}
+
+func (x LinearSlice[T]) Ranges() iter.Seq2[rune, rune] {
+	return runesRanges(x.All())
+}
+
+func (x BinarySlice[T]) All() iter.Seq[rune] {
+	return sliceRunes(x)
+}
+
+func (x BinarySlice[T]) Ranges() iter.Seq2[rune, rune] {
+	return runesRanges(x.All())
+}
+
+func (x RangeSlice[T]) All() iter.Seq[rune] {
+	return rangesRunes(x.Ranges())
+}
+
+func (x RangeSlice[T]) Ranges() iter.Seq2[rune, rune] {
+	// adjacent ranges are allowed, so they need merging
+	return mergeRanges(func(yield func(rune, rune) bool) {
+		for _, v := range x {
+			if !yield(rune(v.From), rune(v.To)) {
+				return
+			}
+		}
+	})
+}
+
+func (x StridedRanges[T]) All() iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		for _, v := range x {
+			for r := range v.All() {
+				if !yield(r) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (x StridedRanges[T]) Ranges() iter.Seq2[rune, rune] {
+	return mergeRanges(func(yield func(rune, rune) bool) {
+		for _, v := range x {
+			for lo, hi := range v.Ranges() {
+				if !yield(lo, hi) {
+					return
+				}
+			}
+		}
+	})
+}
+
+func sliceRunes[S ~[]T, T RuneT](x S) iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		for _, v := range x {
+			if !yield(rune(v)) {
+				return
+			}
+		}
+	}
+}
+
+func (x Interval[T]) All() iter.Seq[rune] {
+	return rangesRunes(x.Ranges())
+}
+
+func (x Interval[T]) Ranges() iter.Seq2[rune, rune] {
+	return func(yield func(rune, rune) bool) {
+		if x.From <= x.To {
+			yield(rune(x.From), rune(x.To))
+		}
+	}
+}
+
+func (x Uniform[T]) All() iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		// iterate over the count, since adding the stride to the last rune
+		// may overflow
+		for i := range x.Len() {
+			if !yield(rune(x.Lo) + rune(i)*rune(x.Stride)) {
+				return
+			}
+		}
+	}
+}
+
+func (x Uniform[T]) Ranges() iter.Seq2[rune, rune] {
+	if x.Stride == 1 {
+		return Interval[T]{x.Lo, x.Hi}.Ranges()
+	}
+	return runesRanges(x.All())
+}
+
+func (x Bitmap) All() iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		if len(x) < bmHdrLen {
+			return
+		}
+		lo := bmDecodeMinRune(x[0], x[1], x[2]&lsb5)
+		for i := bmHdrLen; i < len(x); i++ {
+			base := lo + rune(i-bmHdrLen)<<3
+			for b := x[i]; b != 0; b &= b - 1 {
+				if !yield(base + rune(bits.TrailingZeros8(b))) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (x Bitmap) Ranges() iter.Seq2[rune, rune] {
+	return runesRanges(x.All())
+}
+
+func (x TwoLevel) All() iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		for i := range x.Index {
+			base := rune(x.First+uint32(i)) << blockBits
+			for b := x.leaf(i); b != 0; b &= b - 1 {
+				if !yield(base + rune(bits.TrailingZeros64(b))) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (x TwoLevel) Ranges() iter.Seq2[rune, rune] {
+	return runesRanges(x.All())
+}
+
+func (x SparseBitmap) All() iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		for i, block := range x.Blocks {
+			base := rune(block) << blockBits
+			for b := x.word(i); b != 0; b &= b - 1 {
+				if !yield(base + rune(bits.TrailingZeros64(b))) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (x SparseBitmap) Ranges() iter.Seq2[rune, rune] {
+	return runesRanges(x.All())
+}
+
+func (x Roaring) All() iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		for i, key := range x.Keys {
+			base := rune(key) << roaringBits
+			for v := range x.container(i).all() {
+				if !yield(base + rune(v)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (x Roaring) Ranges() iter.Seq2[rune, rune] {
+	return runesRanges(x.All())
+}
+
+// all returns an iterator over the offsets of the container.
+func (c RoaringContainer) all() iter.Seq[uint16] {
+	return func(yield func(uint16) bool) {
+		switch c.Kind {
+		case RoaringBitmap:
+			for i, w := range c.Data {
+				for ; w != 0; w &= w - 1 {
+					if !yield(uint16(i)<<4 + uint16(bits.TrailingZeros16(w))) {
+						return
+					}
+				}
+			}
+		case RoaringRuns:
+			for i := 0; i+1 < len(c.Data); i += 2 {
+				for v := uint32(c.Data[i]); v <= uint32(c.Data[i+1]); v++ {
+					if !yield(uint16(v)) {
+						return
+					}
+				}
+			}
+		default:
+			for _, v := range c.Data {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (x MixedUnion) All() iter.Seq[rune] {
+	return rangesRunes(x.Ranges())
+}
+
+func (x MixedUnion) Ranges() iter.Seq2[rune, rune] {
+	// segments may be adjacent, so they need merging
+	return mergeRanges(func(yield func(rune, rune) bool) {
+		for i := range x.segments() {
+			for lo, hi := range setRanges(x.segment(i)) {
+				if !yield(lo, hi) {
+					return
+				}
+			}
+		}
+	})
+}
+
+func (x wordBitmap) All() iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		for i, w := range x.words {
+			base := rune(x.lo) + rune(i)<<blockBits
+			for ; w != 0; w &= w - 1 {
+				if !yield(base + rune(bits.TrailingZeros64(w))) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (x wordBitmap) Ranges() iter.Seq2[rune, rune] {
+	return runesRanges(x.All())
+}
+
+// setRunes returns an iterator over the runes of `s` in ascending order. If `s`
+// is not [Enumerable], then all the runes from its Min to its Max are checked.
+func setRunes(s MinMaxSet) iter.Seq[rune] {
+	if e, ok := s.(Enumerable); ok {
+		return e.All()
+	}
+	return func(yield func(rune) bool) {
+		lo, hi := s.Min(), min(s.Max(), utf8.MaxRune)
+		for r := rune(lo); lo <= hi && r <= rune(hi); r++ {
+			if s.Contains(r) && !yield(r) {
+				return
+			}
+		}
+	}
+}
+
+// setRanges is like setRunes, but returns the maximal ranges of `s`.
+func setRanges(s MinMaxSet) iter.Seq2[rune, rune] {
+	if e, ok := s.(Enumerable); ok {
+		return e.Ranges()
+	}
+	return runesRanges(setRunes(s))
+}
+
+// validRanges is like setRanges, but clips the ranges to [0, utf8.MaxRune] and
+// drops those outside of it.
+func validRanges(s MinMaxSet) iter.Seq2[rune, rune] {
+	return func(yield func(rune, rune) bool) {
+		for lo, hi := range setRanges(s) {
+			if hi < 0 || lo > utf8.MaxRune {
+				continue
+			}
+			if !yield(max(lo, 0), min(hi, utf8.MaxRune)) {
+				return
+			}
+		}
+	}
+}
+
+// runesRanges returns the maximal ranges of consecutive runes yielded in
+// ascending order by `seq`.
+func runesRanges(seq iter.Seq[rune]) iter.Seq2[rune, rune] {
+	return mergeRanges(func(yield func(rune, rune) bool) {
+		for r := range seq {
+			if !yield(r, r) {
+				return
+			}
+		}
+	})
+}
+
+// mergeRanges merges the overlapping or adjacent ranges yielded by `seq`,
+// which must be sorted by their lower bound.
+func mergeRanges(seq iter.Seq2[rune, rune]) iter.Seq2[rune, rune] {
+	return func(yield func(rune, rune) bool) {
+		var curLo, curHi rune
+		var started bool
+		for lo, hi := range seq {
+			switch {
+			case !started:
+				curLo, curHi, started = lo, hi, true
+			case curHi == math.MaxInt32 || lo <= curHi+1:
+				curHi = max(curHi, hi)
+			default:
+				if !yield(curLo, curHi) {
+					return
+				}
+				curLo, curHi = lo, hi
+			}
+		}
+		if started {
+			yield(curLo, curHi)
+		}
+	}
+}
+
+// rangesRunes returns an iterator over the runes of the ranges yielded by
+// `seq`.
+func rangesRunes(seq iter.Seq2[rune, rune]) iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		for lo, hi := range seq {
+			for r := lo; r <= hi; r++ {
+				if !yield(r) {
+					return
+				}
+				if r == hi { // r++ would overflow at MaxInt32
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package runes
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+	"testing"
+	"unicode"
+
+	"github.com/diegommm/runes/util"
+)
+
+var _ = []Enumerable{
+	Union[MinMaxSet]{},
+	SortedUnion[MinMaxSet]{},
+	LinearSlice[uint8]{},
+	BinarySlice[uint16]{},
+	RangeSlice[uint8]{},
+	StridedRanges[uint8]{},
+	Interval[uint32]{},
+	Uniform[rune]{},
+	Bitmap(""),
+	TwoLevel{},
+	SparseBitmap{},
+	Roaring{},
+	MixedUnion{},
+}
+
+// minMaxFunc is a [MinMaxSet] that is not [Enumerable].
+type minMaxFunc struct {
+	util.ContainsFunc
+	min, max uint32
+}
+
+func (x minMaxFunc) Min() uint32 { return x.min }
+func (x minMaxFunc) Max() uint32 { return x.max }
+
+func TestEnumerable(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		set    Enumerable
+		runes  []rune
+		ranges [][2]rune
+	}{
+		{LinearSlice[uint8](nil), nil, nil},
+		{LinearSlice[uint8]{1, 2, 3, 5}, []rune{1, 2, 3, 5}, [][2]rune{{1, 3}, {5, 5}}},
+		{BinarySlice[rune]{1, 0x10000, 0x10001}, []rune{1, 0x10000, 0x10001}, [][2]rune{{1, 1}, {0x10000, 0x10001}}},
+		{RangeSlice[uint8](nil), nil, nil},
+		{RangeSlice[uint8]{{1, 2}, {3, 3}, {5, 6}}, []rune{1, 2, 3, 5, 6}, [][2]rune{{1, 3}, {5, 6}}},
+		{StridedRanges[uint8](nil), nil, nil},
+		{StridedRanges[uint8]{{1, 3, 1}, {4, 8, 2}}, []rune{1, 2, 3, 4, 6, 8}, [][2]rune{{1, 4}, {6, 6}, {8, 8}}},
+		{Interval[uint8]{'a', 'c'}, []rune{'a', 'b', 'c'}, [][2]rune{{'a', 'c'}}},
+		{Interval[uint8]{'c', 'a'}, nil, nil},
+		{Uniform[uint8]{}, nil, nil},
+		{Uniform[uint8]{253, 255, 1}, []rune{253, 254, 255}, [][2]rune{{253, 255}}},
+		{Uniform[uint8]{3, 31, 7}, []rune{3, 10, 17, 24, 31}, [][2]rune{{3, 3}, {10, 10}, {17, 17}, {24, 24}, {31, 31}}},
+		{
+			set:    Uniform[rune]{math.MaxInt32 - 3, math.MaxInt32, 2},
+			runes:  []rune{math.MaxInt32 - 3, math.MaxInt32 - 1},
+			ranges: [][2]rune{{math.MaxInt32 - 3, math.MaxInt32 - 3}, {math.MaxInt32 - 1, math.MaxInt32 - 1}},
+		},
+		{
+			set:    Interval[rune]{math.MaxInt32 - 1, math.MaxInt32},
+			runes:  []rune{math.MaxInt32 - 1, math.MaxInt32},
+			ranges: [][2]rune{{math.MaxInt32 - 1, math.MaxInt32}},
+		},
+		{
+			set:    RangeSlice[rune]{{math.MaxInt32 - 3, math.MaxInt32 - 3}, {math.MaxInt32 - 1, math.MaxInt32}},
+			runes:  []rune{math.MaxInt32 - 3, math.MaxInt32 - 1, math.MaxInt32},
+			ranges: [][2]rune{{math.MaxInt32 - 3, math.MaxInt32 - 3}, {math.MaxInt32 - 1, math.MaxInt32}},
+		},
+		{
+			set:    Union[MinMaxSet]{Interval[rune]{math.MaxInt32 - 1, math.MaxInt32}, LinearSlice[rune]{math.MaxInt32}},
+			runes:  []rune{math.MaxInt32 - 1, math.MaxInt32},
+			ranges: [][2]rune{{math.MaxInt32 - 1, math.MaxInt32}},
+		},
+		{NewBitmap(nil), nil, nil},
+		{NewBitmap([]rune{1, 7, 8, 9, 17}), []rune{1, 7, 8, 9, 17}, [][2]rune{{1, 1}, {7, 9}, {17, 17}}},
+		{NewTwoLevel(nil), nil, nil},
+		{NewSparseBitmap(nil), nil, nil},
+		{NewRoaring(nil), nil, nil},
+		{NewRoaring([]rune{1, 63, 64, 200, 0x10000}), []rune{1, 63, 64, 200, 0x10000}, [][2]rune{{1, 1}, {63, 64}, {200, 200}, {0x10000, 0x10000}}},
+		{NewSparseBitmap([]rune{1, 63, 64, 200, 0x10000}), []rune{1, 63, 64, 200, 0x10000}, [][2]rune{{1, 1}, {63, 64}, {200, 200}, {0x10000, 0x10000}}},
+		{NewTwoLevel([]rune{1, 63, 64, 200, 0x10000}), []rune{1, 63, 64, 200, 0x10000}, [][2]rune{{1, 1}, {63, 64}, {200, 200}, {0x10000, 0x10000}}},
+		{Union[MinMaxSet](nil), nil, nil},
+		{
+			set:    Union[MinMaxSet]{Interval[uint8]{1, 3}, Interval[uint8]{4, 5}, LinearSlice[uint8]{7, 9}},
+			runes:  []rune{1, 2, 3, 4, 5, 7, 9},
+			ranges: [][2]rune{{1, 5}, {7, 7}, {9, 9}},
+		},
+		{
+			set:    Union[MinMaxSet]{Interval[uint8]{1, 3}, LinearSlice[uint8]{2, 9}, Interval[uint8]{5, 8}},
+			runes:  []rune{1, 2, 3, 5, 6, 7, 8, 9},
+			ranges: [][2]rune{{1, 3}, {5, 9}},
+		},
+		{
+			set: Union[MinMaxSet]{minMaxFunc{
+				ContainsFunc: func(r rune) bool { return r%2 == 0 },
+				min:          2,
+				max:          6,
+			}},
+			runes:  []rune{2, 4, 6},
+			ranges: [][2]rune{{2, 2}, {4, 4}, {6, 6}},
+		},
+		{MixedUnion{}, nil, nil},
+		{mixedUnion, mixedUnionRunes, [][2]rune{{'a', 'z'}, {0x100, 0x100}, {0x102, 0x102}, {0x104, 0x104}, {0x106, 0x106}, {0x1000, 0x1000}, {0x1234, 0x1234}, {0x1999, 0x1999}, {0x2001, 0x2002}, {0x2004, 0x2004}, {0x2081, 0x2081}}},
+		{MustSortedUnion[MinMaxSet](), nil, nil},
+		{
+			set:    MustSortedUnion[MinMaxSet](Interval[uint8]{1, 3}, Interval[uint8]{4, 5}, LinearSlice[uint8]{7, 9}),
+			runes:  []rune{1, 2, 3, 4, 5, 7, 9},
+			ranges: [][2]rune{{1, 5}, {7, 7}, {9, 9}},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			gotRunes := slices.Collect(tc.set.All())
+			util.Equal(t, true, slices.Equal(tc.runes, gotRunes), "All: %v", gotRunes)
+			gotRanges := collectRanges(tc.set.Ranges())
+			util.Equal(t, true, slices.Equal(tc.ranges, gotRanges), "Ranges: %v", gotRanges)
+		})
+	}
+}
+
+func TestEnumerableCompile(t *testing.T) {
+	t.Parallel()
+	tables := []*unicode.RangeTable{
+		unicode.White_Space,
+		unicode.Greek,
+		unicode.Upper,
+		unicode.Letter,
+	}
+
+	for i, rt := range tables {
+		rs := slices.Collect(util.RangeTableIter(rt))
+		for _, stepCost := range []int{0, defaultStepCost, 1000} {
+			got := slices.Collect(Compile(rs, StepCost(stepCost)).(Enumerable).All())
+			util.Equal(t, true, slices.Equal(rs, got), "index=%v; StepCost=%v", i, stepCost)
+		}
+	}
+}
+
+func TestEnumerableStop(t *testing.T) {
+	t.Parallel()
+	s := Union[MinMaxSet]{Interval[uint8]{1, 3}, NewBitmap([]rune{5, 7})}
+	for range s.All() {
+		break
+	}
+	for range s.Ranges() {
+		break
+	}
+}
+
+func collectRanges(seq iter.Seq2[rune, rune]) [][2]rune {
+	var res [][2]rune
+	for lo, hi := range seq {
+		res = append(res, [2]rune{lo, hi})
+	}
+	return res
+}
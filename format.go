@@ -0,0 +1,222 @@
+package runes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x Union[T]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x Union[T]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x SortedUnion[T]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x SortedUnion[T]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x LinearSlice[T]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x LinearSlice[T]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x BinarySlice[T]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x BinarySlice[T]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x RangeSlice[T]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x RangeSlice[T]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x StridedRanges[T]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x StridedRanges[T]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x Interval[T]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x Interval[T]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x Uniform[T]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x Uniform[T]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern], like `[\t-\r\x20A-Z\u0085]`. Ranges of three or more runes
+// are written as ranges. Printable ASCII runes are written as is, escaping
+// those with a meaning in patterns, and the rest are escaped.
+func (x Bitmap) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. The verbs are:
+//   - %v and %s: the result of String. With the '#' flag, %v uses GoString.
+//   - %q: the result of String, quoted.
+//   - %x and %X: the ranges of the set in hexadecimal, separated by spaces,
+//     like "9-d 20 85".
+//   - %U: the ranges of the set in Unicode format, separated by spaces, like
+//     "U+0009..U+000D U+0020 U+0085".
+func (x Bitmap) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x TwoLevel) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x TwoLevel) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x SparseBitmap) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x SparseBitmap) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x Roaring) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x Roaring) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x MixedUnion) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x MixedUnion) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x AndSet[A, B]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x AndSet[A, B]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x OrSet[A, B]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x OrSet[A, B]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// String returns the set as a bracket expression that can be parsed with
+// [ParsePattern].
+func (x NotSet[S]) String() string { return patternString(x) }
+
+// Format implements fmt.Formatter. See [Bitmap.Format] for the verbs.
+func (x NotSet[S]) Format(f fmt.State, verb rune) { formatSet(f, verb, x) }
+
+// formatSet implements fmt.Formatter for all the sets.
+func formatSet(f fmt.State, verb rune, s MinMaxSet) {
+	switch verb {
+	case 'v':
+		if f.Flag('#') {
+			fmt.Fprint(f, goString(s))
+			return
+		}
+		fallthrough
+	case 's', 'q':
+		fmt.Fprintf(f, fmt.FormatString(f, verb), patternString(s))
+	case 'x', 'X', 'U':
+		var sb strings.Builder
+		for lo, hi := range validRanges(s) {
+			if sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			switch {
+			case verb == 'U' && lo == hi:
+				fmt.Fprintf(&sb, "%U", lo)
+			case verb == 'U':
+				fmt.Fprintf(&sb, "%U..%U", lo, hi)
+			case lo == hi:
+				fmt.Fprintf(&sb, "%"+string(verb), lo)
+			default:
+				fmt.Fprintf(&sb, "%[1]"+string(verb)+"-%[2]"+string(verb), lo, hi)
+			}
+		}
+		fmt.Fprint(f, sb.String())
+	default:
+		fmt.Fprintf(f, "%%!%c(%s)", verb, patternString(s))
+	}
+}
+
+// patternString returns the canonical bracket expression of `s`.
+func patternString(s MinMaxSet) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for lo, hi := range validRanges(s) {
+		writePatternRune(&sb, lo)
+		switch {
+		case hi == lo+1:
+			writePatternRune(&sb, hi)
+		case hi > lo:
+			sb.WriteByte('-')
+			writePatternRune(&sb, hi)
+		}
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// writePatternRune writes a rune of a bracket expression.
+func writePatternRune(sb *strings.Builder, r rune) {
+	switch r {
+	case '[', ']', '\\', '-', '^', '&', ':':
+		sb.WriteByte('\\')
+		sb.WriteByte(byte(r))
+		return
+	case '\t':
+		sb.WriteString(`\t`)
+		return
+	case '\n':
+		sb.WriteString(`\n`)
+		return
+	case '\v':
+		sb.WriteString(`\v`)
+		return
+	case '\f':
+		sb.WriteString(`\f`)
+		return
+	case '\r':
+		sb.WriteString(`\r`)
+		return
+	}
+	var prefix string
+	var digits int
+	switch {
+	case r > ' ' && r < utf8.RuneSelf-1:
+		sb.WriteByte(byte(r))
+		return
+	case r >= 0 && r < utf8.RuneSelf:
+		prefix, digits = `\x`, 2
+	case r >= 0 && r <= 0xffff:
+		prefix, digits = `\u`, 4
+	default:
+		prefix, digits = `\U`, 8
+	}
+	hex := strings.ToUpper(strconv.FormatUint(uint64(uint32(r)), 16))
+	sb.WriteString(prefix)
+	sb.WriteString(strings.Repeat("0", max(digits-len(hex), 0)))
+	sb.WriteString(hex)
+}
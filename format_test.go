@@ -0,0 +1,79 @@
+package runes
+
+import (
+	"fmt"
+	"testing"
+	"unicode"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	ws := FromRangeTable(unicode.White_Space)
+	testCases := []struct {
+		format   string
+		set      MinMaxSet
+		expected string
+	}{
+		{"%v", LinearSlice[uint8](nil), "[]"},
+		{"%v", Interval[uint8]{'a', 'z'}, "[a-z]"},
+		{"%s", Interval[uint8]{'a', 'b'}, "[ab]"},
+		{"%v", LinearSlice[uint8]{'&', '-', ':', '[', '\\', ']', '^', 'a'}, `[\&\-\:\[-\^a]`},
+		{"%v", LinearSlice[uint8]{0, 0x7f, 0x80}, `[\x00\x7F\u0080]`},
+		{"%v", LinearSlice[rune]{0xffff, 0x10000, 0x10ffff}, `[\uFFFF\U00010000\U0010FFFF]`},
+		{"%v", Uniform[uint8]{'a', 'e', 2}, "[ace]"},
+		{"%v", ws, `[\t-\r\x20\u0085\u00A0\u1680\u2000-\u200A\u2028\u2029\u202F\u205F\u3000]`},
+		{"%q", Interval[uint8]{'a', 'c'}, `"[a-c]"`},
+		{"%8s", Interval[uint8]{'a', 'c'}, "   [a-c]"},
+		{"%x", LinearSlice[uint8]{9, 10, 11, 0x20, 0xaa}, "9-b 20 aa"},
+		{"%X", LinearSlice[uint8]{0xaa, 0xab}, "AA-AB"},
+		{"%U", LinearSlice[uint8]{9, 10, 11, 0x20}, "U+0009..U+000B U+0020"},
+		{"%#v", Interval[uint8]{'a', 'c'}, "runes.Interval[uint8]{From: 0x61, To: 0x63}"},
+		{"%d", Interval[uint8]{'a', 'c'}, "%!d([a-c])"},
+		{"%v", Union[MinMaxSet]{Interval[uint8]{'a', 'c'}, NewBitmap(nil)}, "[a-c]"},
+		{"%v", MustSortedUnion[MinMaxSet](Interval[uint8]{'a', 'c'}, NewBitmap([]rune{0x100})), `[a-c\u0100]`},
+		{"%v", NewBitmap([]rune{'x', 'z'}), "[xz]"},
+		{"%v", NewTwoLevel([]rune{'x', 'z', 0x100}), `[xz\u0100]`},
+		{"%v", NewSparseBitmap([]rune{'x', 'z', 0x3000}), `[xz\u3000]`},
+		{"%v", NewRoaring([]rune{'x', 'y', 'z', 0x3000}), `[x-z\u3000]`},
+		{"%v", NewMixedUnion([]rune{'x', 'y', 'z', 0x3000}), `[x-z\u3000]`},
+		{"%v", BinarySlice[uint16]{'x', 'y'}, "[xy]"},
+		{"%v", RangeSlice[uint8]{{'a', 'c'}, {'d', 'd'}, {'x', 'y'}}, "[a-dxy]"},
+		{"%v", StridedRanges[uint8]{{'a', 'c', 1}, {'d', 'h', 2}}, "[a-dfh]"},
+		{"%v", Not(Interval[rune]{1, unicode.MaxRune}), `[\x00]`},
+		{"%v", And(Interval[uint8]{'a', 'c'}, Interval[uint8]{'b', 'd'}), "[bc]"},
+		{"%v", LinearSlice[rune]{-5, 'a', 0x110000}, "[a]"},
+		{"%v", Interval[rune]{-3, 'b'}, `[\x00-b]`},
+		{"%x", Interval[rune]{0x10fffe, 0x7fffffff}, "10fffe-10ffff"},
+		{"%U", LinearSlice[rune]{-1, 0x41}, "U+0041"},
+		{"%v", Or(Interval[uint8]{'a', 'c'}, Interval[uint8]{'x', 'y'}), "[a-cxy]"},
+	}
+
+	for i, tc := range testCases {
+		got := fmt.Sprintf(tc.format, tc.set)
+		util.Equal(t, tc.expected, got, "index=%v; unexpected output", i)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	t.Parallel()
+	for name, rt := range util.Tables {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := FromRangeTable(rt)
+			got, err := ParsePattern(s.(fmt.Stringer).String())
+			util.MustEqual(t, nil, err, "ParsePattern")
+			util.Equal(t, true, Equal(s, got), "round trip failed")
+		})
+	}
+
+	// every rune of the Basic Multilingual Plane, one at a time
+	for r := rune(0); r <= 0x10000; r++ {
+		s := LinearSlice[rune]{r}
+		got, err := ParsePattern(s.String())
+		if err != nil || !Equal(s, got) {
+			util.MustEqual(t, "", s.String(), "round trip failed with error %v", err)
+		}
+	}
+}
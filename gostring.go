@@ -0,0 +1,266 @@
+package runes
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pkgPath is the import path of this package, as shown by reflect in type
+// arguments.
+const pkgPath = "github.com/diegommm/runes."
+
+// goTypeName returns the name of T qualified with the package name, as it
+// would be written in Go source code outside this package.
+func goTypeName[T any]() string {
+	name := reflect.TypeFor[T]().String()
+	return strings.ReplaceAll(name, pkgPath, "runes.")
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x Union[T]) GoString() string {
+	var sb strings.Builder
+	sb.WriteString(goTypeName[Union[T]]())
+	sb.WriteByte('{')
+	for i := range x {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(goString(x[i]))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x SortedUnion[T]) GoString() string {
+	var sb strings.Builder
+	sb.WriteString("runes.MustSortedUnion[" + goTypeName[T]() + "](")
+	for i := range x.elems {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(goString(x.elems[i]))
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x LinearSlice[T]) GoString() string {
+	return goTypeName[LinearSlice[T]]() + goRuneTs(x)
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x BinarySlice[T]) GoString() string {
+	return goTypeName[BinarySlice[T]]() + goRuneTs(x)
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x RangeSlice[T]) GoString() string {
+	var sb strings.Builder
+	sb.WriteString(goTypeName[RangeSlice[T]]())
+	sb.WriteByte('{')
+	for i, v := range x {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("{From: " + goRuneT(v.From) + ", To: " + goRuneT(v.To) + "}")
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x StridedRanges[T]) GoString() string {
+	var sb strings.Builder
+	sb.WriteString(goTypeName[StridedRanges[T]]())
+	sb.WriteByte('{')
+	for i, v := range x {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("{Lo: " + goRuneT(v.Lo) + ", Hi: " + goRuneT(v.Hi) +
+			", Stride: " + goRuneT(v.Stride) + "}")
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x Interval[T]) GoString() string {
+	return goTypeName[Interval[T]]() + "{From: " + goRuneT(x.From) +
+		", To: " + goRuneT(x.To) + "}"
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x Uniform[T]) GoString() string {
+	return goTypeName[Uniform[T]]() + "{Lo: " + goRuneT(x.Lo) +
+		", Hi: " + goRuneT(x.Hi) + ", Stride: " + goRuneT(x.Stride) + "}"
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x Bitmap) GoString() string {
+	var sb strings.Builder
+	sb.WriteString("runes.Bitmap(\"")
+	for i := range len(x) {
+		sb.WriteString(`\x`)
+		sb.WriteString(strconv.FormatUint(uint64(x[i])|0x100, 16)[1:])
+	}
+	sb.WriteString("\")")
+	return sb.String()
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x TwoLevel) GoString() string {
+	var sb strings.Builder
+	sb.WriteString("runes.TwoLevel{First: " + goRuneT(x.First) + ", Index: []uint16")
+	sb.WriteString(goRuneTs(x.Index))
+	sb.WriteString(", Leaves: []uint64")
+	sb.WriteString(goUint64s(x.Leaves))
+	sb.WriteString(goRanks(x.Ranks) + "}")
+	return sb.String()
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x SparseBitmap) GoString() string {
+	return "runes.SparseBitmap{Blocks: []uint16" + goRuneTs(x.Blocks) +
+		", Words: []uint64" + goUint64s(x.Words) + goRanks(x.Ranks) + "}"
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x Roaring) GoString() string {
+	var sb strings.Builder
+	sb.WriteString("runes.Roaring{Keys: []uint16" + goRuneTs(x.Keys) +
+		", Containers: []runes.RoaringContainer{")
+	for i, c := range x.Containers {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("{Kind: " + c.Kind.GoString() + ", Data: []uint16" + goRuneTs(c.Data) + "}")
+	}
+	sb.WriteString("}" + goRanks(x.Ranks) + "}")
+	return sb.String()
+}
+
+// GoString returns the name of the constant of the kind, qualified with the
+// package name.
+func (k RoaringKind) GoString() string {
+	switch k {
+	case RoaringArray:
+		return "runes.RoaringArray"
+	case RoaringBitmap:
+		return "runes.RoaringBitmap"
+	case RoaringRuns:
+		return "runes.RoaringRuns"
+	}
+	return "runes.RoaringKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x MixedUnion) GoString() string {
+	var sb strings.Builder
+	sb.WriteString("runes.MixedUnion{Kinds: []runes.MixedKind{")
+	for i, k := range x.Kinds {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(k.GoString())
+	}
+	sb.WriteString("}, Bounds: []uint32" + goRuneTs(x.Bounds))
+	sb.WriteString(", Args: []uint32" + goRuneTs(x.Args))
+	sb.WriteString(", Runes: []uint32" + goRuneTs(x.Runes))
+	sb.WriteString(", Words: []uint64" + goUint64s(x.Words) + goRanks(x.Ranks) + "}")
+	return sb.String()
+}
+
+// GoString returns the name of the constant of the kind, qualified with the
+// package name.
+func (k MixedKind) GoString() string {
+	switch k {
+	case MixedInterval:
+		return "runes.MixedInterval"
+	case MixedStrided:
+		return "runes.MixedStrided"
+	case MixedSlice:
+		return "runes.MixedSlice"
+	case MixedBitmap:
+		return "runes.MixedBitmap"
+	}
+	return "runes.MixedKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x AndSet[A, B]) GoString() string {
+	return "runes.And(" + goString(x.a) + ", " + goString(x.b) + ")"
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x OrSet[A, B]) GoString() string {
+	return "runes.Or(" + goString(x.a) + ", " + goString(x.b) + ")"
+}
+
+// GoString returns a Go expression that evaluates to the set.
+func (x NotSet[S]) GoString() string {
+	return "runes.Not(" + goString(x.s) + ")"
+}
+
+// goString returns the GoString of `s`, or a comment if it does not implement
+// it.
+func goString(s Set) string {
+	if gs, ok := s.(interface{ GoString() string }); ok {
+		return gs.GoString()
+	}
+	return "nil /* " + reflect.TypeOf(s).String() + " */"
+}
+
+func goRuneT[T RuneT](v T) string {
+	if v < 0 { // only possible with rune
+		return "-0x" + strconv.FormatInt(-int64(v), 16)
+	}
+	return "0x" + strconv.FormatInt(int64(v), 16)
+}
+
+func goRuneTs[T RuneT](vs []T) string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, v := range vs {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(goRuneT(v))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func goUint64s(vs []uint64) string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, v := range vs {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("0x" + strconv.FormatUint(v, 16))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// goRanks returns the Ranks field of a struct literal, or nothing if there are
+// no ranks.
+func goRanks(ranks []uint32) string {
+	if len(ranks) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(", Ranks: []uint32{")
+	for i, v := range ranks {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(strconv.FormatUint(uint64(v), 10))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
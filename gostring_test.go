@@ -0,0 +1,67 @@
+package runes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestGoString(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		set      Set
+		expected string
+	}{
+		{Union[MinMaxSet](nil), "runes.Union[runes.MinMaxSet]{}"},
+		{
+			set:      Union[MinMaxSet]{Interval[uint8]{'a', 'z'}, NewBitmap([]rune{0x100, 0x102})},
+			expected: `runes.Union[runes.MinMaxSet]{runes.Interval[uint8]{From: 0x61, To: 0x7a}, runes.Bitmap("\x00\x01\x40\x05")}`,
+		},
+		{
+			set:      Union[Interval[uint16]]{{1, 2}, {0x100, 0x200}},
+			expected: "runes.Union[runes.Interval[uint16]]{runes.Interval[uint16]{From: 0x1, To: 0x2}, runes.Interval[uint16]{From: 0x100, To: 0x200}}",
+		},
+		{MustSortedUnion[MinMaxSet](), "runes.MustSortedUnion[runes.MinMaxSet]()"},
+		{
+			set:      MustSortedUnion(Interval[uint16]{1, 2}, Interval[uint16]{0x100, 0x200}),
+			expected: "runes.MustSortedUnion[runes.Interval[uint16]](runes.Interval[uint16]{From: 0x1, To: 0x2}, runes.Interval[uint16]{From: 0x100, To: 0x200})",
+		},
+		{LinearSlice[uint8]{9, 10}, "runes.LinearSlice[uint8]{0x9, 0xa}"},
+		{BinarySlice[rune]{-1, 0x10000}, "runes.BinarySlice[int32]{-0x1, 0x10000}"},
+		{RangeSlice[uint8]{{1, 2}, {5, 9}}, "runes.RangeSlice[uint8]{{From: 0x1, To: 0x2}, {From: 0x5, To: 0x9}}"},
+		{StridedRanges[uint8]{{1, 9, 2}}, "runes.StridedRanges[uint8]{{Lo: 0x1, Hi: 0x9, Stride: 0x2}}"},
+		{Interval[uint32]{1, 2}, "runes.Interval[uint32]{From: 0x1, To: 0x2}"},
+		{Uniform[uint16]{1, 9, 2}, "runes.Uniform[uint16]{Lo: 0x1, Hi: 0x9, Stride: 0x2}"},
+		{Bitmap(""), `runes.Bitmap("")`},
+		{SparseBitmap{}, "runes.SparseBitmap{Blocks: []uint16{}, Words: []uint64{}}"},
+		{
+			set:      NewSparseBitmap([]rune{0x41, 0x5a, 0x3000}),
+			expected: "runes.SparseBitmap{Blocks: []uint16{0x1, 0xc0}, Words: []uint64{0x4000002, 0x1}, Ranks: []uint32{0, 2, 3}}",
+		},
+		{Roaring{}, "runes.Roaring{Keys: []uint16{}, Containers: []runes.RoaringContainer{}}"},
+		{
+			set:      NewRoaring([]rune{0x41, 0x5a, 0x3000, 0x3001, 0x3002}),
+			expected: "runes.Roaring{Keys: []uint16{0x0, 0x3}, Containers: []runes.RoaringContainer{{Kind: runes.RoaringArray, Data: []uint16{0x41, 0x5a}}, {Kind: runes.RoaringRuns, Data: []uint16{0x0, 0x2}}}, Ranks: []uint32{0, 2, 5}}",
+		},
+		{MixedUnion{}, "runes.MixedUnion{Kinds: []runes.MixedKind{}, Bounds: []uint32{}, Args: []uint32{}, Runes: []uint32{}, Words: []uint64{}}"},
+		{
+			set:      mixedUnion,
+			expected: "runes.MixedUnion{Kinds: []runes.MixedKind{runes.MixedInterval, runes.MixedStrided, runes.MixedSlice, runes.MixedBitmap}, Bounds: []uint32{0x61, 0x7a, 0x100, 0x106, 0x1000, 0x1999, 0x2001, 0x2081}, Args: []uint32{0x0, 0x2, 0x0, 0x0}, Runes: []uint32{0x1000, 0x1234, 0x1999}, Words: []uint64{0xb, 0x0, 0x1}}",
+		},
+		{TwoLevel{}, "runes.TwoLevel{First: 0x0, Index: []uint16{}, Leaves: []uint64{}}"},
+		{
+			set:      NewTwoLevel([]rune{0x41, 0x5a, 0x100}),
+			expected: "runes.TwoLevel{First: 0x1, Index: []uint16{0x0, 0x1, 0x1, 0x2}, Leaves: []uint64{0x4000002, 0x0, 0x1}, Ranks: []uint32{0, 2, 2, 2, 3}}",
+		},
+		{
+			set:      Or(Interval[uint8]{'a', 'z'}, Not(Interval[uint8]{'m', 'm'})),
+			expected: "runes.Or(runes.Interval[uint8]{From: 0x61, To: 0x7a}, runes.Not(runes.Interval[uint8]{From: 0x6d, To: 0x6d}))",
+		},
+		{And(Interval[uint8]{1, 2}, minMaxFunc{}), "runes.And(runes.Interval[uint8]{From: 0x1, To: 0x2}, nil /* runes.minMaxFunc */)"},
+	}
+
+	for i, tc := range testCases {
+		util.Equal(t, tc.expected, fmt.Sprintf("%#v", tc.set), "index=%v", i)
+	}
+}
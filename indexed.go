@@ -0,0 +1,643 @@
+package runes
+
+import (
+	"cmp"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+func (x Union[T]) Len() int {
+	if !x.sorted() {
+		return rangesLen(x.Ranges())
+	}
+	var n int
+	for i := range x {
+		n += setLen(x[i])
+	}
+	return n
+}
+
+func (x Union[T]) Rank(r rune) int {
+	if r < 0 {
+		return 0
+	}
+	if !x.sorted() {
+		return rangesRank(x.Ranges(), r)
+	}
+	var n int
+	for i := range x {
+		switch {
+		case uint32(r) > x[i].Max():
+			n += setLen(x[i])
+		case uint32(r) > x[i].Min():
+			return n + setRank(x[i], r)
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+func (x Union[T]) Select(i int) (rune, bool) {
+	if !x.sorted() {
+		return rangesSelect(x.Ranges(), i)
+	}
+	for j := 0; i >= 0 && j < len(x); j++ {
+		n := setLen(x[j])
+		if i < n {
+			return setSelect(x[j], i)
+		}
+		i -= n
+	}
+	return 0, false
+}
+
+func (x SortedUnion[T]) Len() int {
+	return ranksLen(x.ranks)
+}
+
+func (x SortedUnion[T]) Rank(r rune) int {
+	if r < 0 || uint32(r) <= x.Min() {
+		return 0
+	}
+	i := x.search(uint32(r))
+	if i == len(x.elems) {
+		return x.Len()
+	}
+	n := int(x.ranks[i])
+	if uint32(r) > x.bounds[2*i] {
+		n += setRank(x.elems[i], r)
+	}
+	return n
+}
+
+func (x SortedUnion[T]) Select(i int) (rune, bool) {
+	j, ok := searchRanks(x.ranks, i)
+	if !ok {
+		return 0, false
+	}
+	return setSelect(x.elems[j], i-int(x.ranks[j]))
+}
+
+func (x LinearSlice[T]) Len() int {
+	return len(x)
+}
+
+func (x LinearSlice[T]) Rank(r rune) int {
+	for i := range x {
+		if rune(x[i]) >= r {
+			return i
+		}
+	}
+	return len(x)
+}
+
+func (x LinearSlice[T]) Select(i int) (rune, bool) {
+	return sliceSelect(x, i)
+}
+
+func (x BinarySlice[T]) Len() int {
+	return len(x)
+}
+
+func (x BinarySlice[T]) Rank(r rune) int {
+	i, _ := slices.BinarySearchFunc(x, r, func(v T, r rune) int {
+		return cmp.Compare(rune(v), r)
+	})
+	return i
+}
+
+func (x BinarySlice[T]) Select(i int) (rune, bool) {
+	return sliceSelect(x, i)
+}
+
+func (x RangeSlice[T]) Len() int {
+	var n int
+	for _, v := range x {
+		n += v.Len()
+	}
+	return n
+}
+
+func (x RangeSlice[T]) Rank(r rune) int {
+	i := x.search(r)
+	var n int
+	for _, v := range x[:i] {
+		n += v.Len()
+	}
+	if i < len(x) {
+		n += x[i].Rank(r)
+	}
+	return n
+}
+
+func (x RangeSlice[T]) Select(i int) (rune, bool) {
+	for j := 0; i >= 0 && j < len(x); j++ {
+		n := x[j].Len()
+		if i < n {
+			return x[j].Select(i)
+		}
+		i -= n
+	}
+	return 0, false
+}
+
+func (x StridedRanges[T]) Len() int {
+	var n int
+	for _, v := range x {
+		n += v.Len()
+	}
+	return n
+}
+
+func (x StridedRanges[T]) Rank(r rune) int {
+	i := x.search(r)
+	var n int
+	for _, v := range x[:i] {
+		n += v.Len()
+	}
+	if i < len(x) {
+		n += x[i].Rank(r)
+	}
+	return n
+}
+
+func (x StridedRanges[T]) Select(i int) (rune, bool) {
+	for j := 0; i >= 0 && j < len(x); j++ {
+		n := x[j].Len()
+		if i < n {
+			return x[j].Select(i)
+		}
+		i -= n
+	}
+	return 0, false
+}
+
+func sliceSelect[S ~[]T, T RuneT](x S, i int) (rune, bool) {
+	if i < 0 || i >= len(x) {
+		return 0, false
+	}
+	return rune(x[i]), true
+}
+
+func (x Interval[T]) Len() int {
+	if x.From > x.To {
+		return 0
+	}
+	return int(x.To-x.From) + 1
+}
+
+func (x Interval[T]) Rank(r rune) int {
+	return int(min(max(int64(r)-int64(x.From), 0), int64(x.Len())))
+}
+
+func (x Interval[T]) Select(i int) (rune, bool) {
+	if i < 0 || i >= x.Len() {
+		return 0, false
+	}
+	return rune(x.From) + rune(i), true
+}
+
+func (x Uniform[T]) Len() int {
+	if x.Stride == 0 || x.Lo > x.Hi {
+		return 0
+	}
+	return int((x.Hi-x.Lo)/x.Stride) + 1
+}
+
+func (x Uniform[T]) Rank(r rune) int {
+	if r <= rune(x.Lo) || x.Stride == 0 {
+		return 0
+	}
+	n := (int64(r) - int64(x.Lo) + int64(x.Stride) - 1) / int64(x.Stride)
+	return int(min(n, int64(x.Len())))
+}
+
+func (x Uniform[T]) Select(i int) (rune, bool) {
+	if i < 0 || i >= x.Len() {
+		return 0, false
+	}
+	return rune(x.Lo) + rune(i)*rune(x.Stride), true
+}
+
+func (x Bitmap) Len() int {
+	if len(x) < bmHdrLen {
+		return 0
+	}
+	return popcount(string(x[bmHdrLen:]))
+}
+
+func (x Bitmap) Rank(r rune) int {
+	if len(x) < bmHdrLen || r < 0 || uint32(r) <= x.Min() {
+		return 0
+	}
+	if uint32(r) > x.Max() {
+		return x.Len()
+	}
+	u := uint32(r) - x.Min()
+	i := bmHdrLen + int(u>>3)
+	return popcount(string(x[bmHdrLen:i])) +
+		bits.OnesCount8(x[i]&(1<<(u&7)-1))
+}
+
+func (x Bitmap) Select(i int) (rune, bool) {
+	if len(x) < bmHdrLen || i < 0 {
+		return 0, false
+	}
+	// skip 8 bytes at a time while there are less than i bits set
+	j := bmHdrLen
+	for ; j+8 <= len(x); j += 8 {
+		n := bits.OnesCount64(load64(string(x[j:])))
+		if i < n {
+			break
+		}
+		i -= n
+	}
+	for ; j < len(x); j++ {
+		n := bits.OnesCount8(x[j])
+		if i >= n {
+			i -= n
+			continue
+		}
+		b := x[j]
+		for ; i > 0; i-- {
+			b &= b - 1 // clear lowest bit set
+		}
+		return rune(x.Min()) + rune(j-bmHdrLen)<<3 + rune(bits.TrailingZeros8(b)), true
+	}
+	return 0, false
+}
+
+func (x TwoLevel) Len() int {
+	return ranksLen(x.ranks())
+}
+
+func (x TwoLevel) Rank(r rune) int {
+	if len(x.Index) == 0 || r < 0 || uint32(r) <= x.Min() {
+		return 0
+	}
+	if uint32(r) > x.Max() {
+		return x.Len()
+	}
+	i := int(uint32(r)>>blockBits - x.First)
+	return int(x.ranks()[i]) + bits.OnesCount64(x.leaf(i)&(1<<(uint32(r)&blockMask)-1))
+}
+
+func (x TwoLevel) Select(i int) (rune, bool) {
+	ranks := x.ranks()
+	j, ok := searchRanks(ranks, i)
+	if !ok {
+		return 0, false
+	}
+	return rune(x.First+uint32(j))<<blockBits + selectBit(x.leaf(j), i-int(ranks[j])), true
+}
+
+// ranks returns Ranks, or computes them if they are missing.
+func (x TwoLevel) ranks() []uint32 {
+	if len(x.Ranks) == len(x.Index)+1 {
+		return x.Ranks
+	}
+	return cumulative(len(x.Index), func(i int) int { return bits.OnesCount64(x.leaf(i)) })
+}
+
+func (x SparseBitmap) Len() int {
+	return ranksLen(x.ranks())
+}
+
+func (x SparseBitmap) Rank(r rune) int {
+	if r < 0 || uint32(r) <= x.Min() {
+		return 0
+	}
+	block := uint32(r) >> blockBits
+	i := x.search(block)
+	n := int(x.ranks()[i])
+	if i < len(x.Blocks) && uint32(x.Blocks[i]) == block {
+		n += bits.OnesCount64(x.word(i) & (1<<(uint32(r)&blockMask) - 1))
+	}
+	return n
+}
+
+func (x SparseBitmap) Select(i int) (rune, bool) {
+	ranks := x.ranks()
+	j, ok := searchRanks(ranks, i)
+	if !ok {
+		return 0, false
+	}
+	return rune(x.Blocks[j])<<blockBits + selectBit(x.word(j), i-int(ranks[j])), true
+}
+
+// ranks returns Ranks, or computes them if they are missing.
+func (x SparseBitmap) ranks() []uint32 {
+	if len(x.Ranks) == len(x.Blocks)+1 {
+		return x.Ranks
+	}
+	return cumulative(len(x.Blocks), func(i int) int { return bits.OnesCount64(x.word(i)) })
+}
+
+// selectBit returns the position of the i-th bit set of `w`, which must have
+// more than `i` bits set.
+func selectBit(w uint64, i int) rune {
+	for ; i > 0; i-- {
+		w &= w - 1 // clear lowest bit set
+	}
+	return rune(bits.TrailingZeros64(w))
+}
+
+func (x Roaring) Len() int {
+	return ranksLen(x.ranks())
+}
+
+func (x Roaring) Rank(r rune) int {
+	if r < 0 || uint32(r) <= x.Min() {
+		return 0
+	}
+	key := uint32(r) >> roaringBits
+	i := x.search(key)
+	n := int(x.ranks()[i])
+	if i < len(x.Keys) && uint32(x.Keys[i]) == key {
+		n += x.container(i).rank(uint16(r & roaringMask))
+	}
+	return n
+}
+
+func (x Roaring) Select(i int) (rune, bool) {
+	ranks := x.ranks()
+	j, ok := searchRanks(ranks, i)
+	if !ok {
+		return 0, false
+	}
+	return rune(x.Keys[j])<<roaringBits + rune(x.container(j).sel(i-int(ranks[j]))), true
+}
+
+// ranks returns Ranks, or computes them if they are missing.
+func (x Roaring) ranks() []uint32 {
+	if len(x.Ranks) == len(x.Keys)+1 {
+		return x.Ranks
+	}
+	return cumulative(len(x.Keys), func(i int) int { return x.container(i).len() })
+}
+
+// len returns the number of runes of the container.
+func (c RoaringContainer) len() int {
+	switch c.Kind {
+	case RoaringBitmap:
+		var n int
+		for _, w := range c.Data {
+			n += bits.OnesCount16(w)
+		}
+		return n
+	case RoaringRuns:
+		var n int
+		for i := 0; i+1 < len(c.Data); i += 2 {
+			n += int(c.Data[i+1]) - int(c.Data[i]) + 1
+		}
+		return n
+	default:
+		return len(c.Data)
+	}
+}
+
+// rank returns the number of offsets of the container smaller than `v`.
+func (c RoaringContainer) rank(v uint16) int {
+	switch c.Kind {
+	case RoaringBitmap:
+		var n int
+		i := min(int(v>>4), len(c.Data))
+		for _, w := range c.Data[:i] {
+			n += bits.OnesCount16(w)
+		}
+		if i < len(c.Data) {
+			n += bits.OnesCount16(c.Data[i] & (1<<(v&15) - 1))
+		}
+		return n
+	case RoaringRuns:
+		var n int
+		for i := 0; i+1 < len(c.Data) && c.Data[i] < v; i += 2 {
+			n += int(min(c.Data[i+1], v-1)) - int(c.Data[i]) + 1
+		}
+		return n
+	default:
+		i, _ := slices.BinarySearch(c.Data, v)
+		return i
+	}
+}
+
+// sel returns the i-th offset of the container, which must be less than its
+// length.
+func (c RoaringContainer) sel(i int) uint16 {
+	switch c.Kind {
+	case RoaringBitmap:
+		for j, w := range c.Data {
+			if n := bits.OnesCount16(w); i >= n {
+				i -= n
+				continue
+			}
+			for ; i > 0; i-- {
+				w &= w - 1 // clear lowest bit set
+			}
+			return uint16(j)<<4 + uint16(bits.TrailingZeros16(w))
+		}
+	case RoaringRuns:
+		for j := 0; j+1 < len(c.Data); j += 2 {
+			if n := int(c.Data[j+1]) - int(c.Data[j]) + 1; i >= n {
+				i -= n
+				continue
+			}
+			return c.Data[j] + uint16(i)
+		}
+	default:
+		if i < len(c.Data) {
+			return c.Data[i]
+		}
+	}
+	return 0
+}
+
+func (x MixedUnion) Len() int {
+	return ranksLen(x.ranks())
+}
+
+func (x MixedUnion) Rank(r rune) int {
+	if r < 0 || uint32(r) <= x.Min() {
+		return 0
+	}
+	ranks := x.ranks()
+	i := min(searchBounds(x.Bounds, uint32(r)), x.segments())
+	n := int(ranks[i])
+	if i < x.segments() && uint32(r) > x.Bounds[2*i] {
+		n += setRank(x.segment(i), r)
+	}
+	return n
+}
+
+func (x MixedUnion) Select(i int) (rune, bool) {
+	ranks := x.ranks()
+	j, ok := searchRanks(ranks, i)
+	if !ok {
+		return 0, false
+	}
+	return setSelect(x.segment(j), i-int(ranks[j]))
+}
+
+// ranks returns Ranks, or computes them if they are missing.
+func (x MixedUnion) ranks() []uint32 {
+	if len(x.Ranks) == x.segments()+1 {
+		return x.Ranks
+	}
+	return cumulative(x.segments(), func(i int) int { return setLen(x.segment(i)) })
+}
+
+func (x wordBitmap) Len() int {
+	var n int
+	for _, w := range x.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+func (x wordBitmap) Rank(r rune) int {
+	if r < 0 || uint32(r) <= x.lo {
+		return 0
+	}
+	off := uint32(r) - x.lo
+	i := min(int(off>>blockBits), len(x.words))
+	var n int
+	for _, w := range x.words[:i] {
+		n += bits.OnesCount64(w)
+	}
+	if i < len(x.words) {
+		n += bits.OnesCount64(x.words[i] & (1<<(off&blockMask) - 1))
+	}
+	return n
+}
+
+func (x wordBitmap) Select(i int) (rune, bool) {
+	if i < 0 {
+		return 0, false
+	}
+	for j, w := range x.words {
+		if n := bits.OnesCount64(w); i >= n {
+			i -= n
+			continue
+		}
+		for ; i > 0; i-- {
+			w &= w - 1 // clear lowest bit set
+		}
+		return rune(x.lo) + rune(j)<<blockBits + rune(bits.TrailingZeros64(w)), true
+	}
+	return 0, false
+}
+
+// popcount returns the number of bits set in `s`.
+func popcount(s string) int {
+	var n int
+	for ; len(s) >= 8; s = s[8:] {
+		n += bits.OnesCount64(load64(s))
+	}
+	for i := range len(s) {
+		n += bits.OnesCount8(s[i])
+	}
+	return n
+}
+
+// load64 decodes the first 8 bytes of `s` as a little-endian uint64.
+func load64(s string) uint64 {
+	_ = s[7] // bounds check hint to compiler
+	return uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 |
+		uint64(s[3])<<24 | uint64(s[4])<<32 | uint64(s[5])<<40 |
+		uint64(s[6])<<48 | uint64(s[7])<<56
+}
+
+// cumulative returns the number of runes before each of `n` elements, given
+// the number of runes of each of them, followed by the total number of runes.
+func cumulative(n int, count func(i int) int) []uint32 {
+	ranks := make([]uint32, n+1)
+	for i := range n {
+		ranks[i+1] = ranks[i] + uint32(count(i))
+	}
+	return ranks
+}
+
+// ranksLen returns the total number of runes of the elements with the given
+// cumulative counts.
+func ranksLen(ranks []uint32) int {
+	if len(ranks) == 0 {
+		return 0
+	}
+	return int(ranks[len(ranks)-1])
+}
+
+// searchRanks returns the index of the element that has the i-th rune, given
+// the cumulative counts of the elements, and true. The counts may come from an
+// exported Ranks field, so it returns false if they do not place the i-th rune
+// in an element.
+func searchRanks(ranks []uint32, i int) (int, bool) {
+	if i < 0 || i >= ranksLen(ranks) {
+		return 0, false
+	}
+	// the first element whose count exceeds `i` is the one after
+	j, _ := slices.BinarySearch(ranks, uint32(i)+1)
+	j--
+	if j < 0 || j >= len(ranks)-1 || int(ranks[j]) > i || int(ranks[j+1]) <= i {
+		return 0, false
+	}
+	return j, true
+}
+
+// setLen returns the number of runes of `s`, using [Indexed] if implemented.
+func setLen(s MinMaxSet) int {
+	if x, ok := s.(Indexed); ok {
+		return x.Len()
+	}
+	return rangesLen(setRanges(s))
+}
+
+// setRank is like setLen, but returns the rank of `r` in `s`.
+func setRank(s MinMaxSet, r rune) int {
+	if x, ok := s.(Indexed); ok {
+		return x.Rank(r)
+	}
+	return rangesRank(setRanges(s), r)
+}
+
+// setSelect is like setLen, but returns the i-th rune of `s`.
+func setSelect(s MinMaxSet, i int) (rune, bool) {
+	if x, ok := s.(Indexed); ok {
+		return x.Select(i)
+	}
+	return rangesSelect(setRanges(s), i)
+}
+
+func rangesLen(seq iter.Seq2[rune, rune]) int {
+	var n int
+	for lo, hi := range seq {
+		n += int(hi-lo) + 1
+	}
+	return n
+}
+
+func rangesRank(seq iter.Seq2[rune, rune], r rune) int {
+	var n int
+	for lo, hi := range seq {
+		if r <= lo {
+			break
+		}
+		n += int(min(hi+1, r) - lo)
+	}
+	return n
+}
+
+func rangesSelect(seq iter.Seq2[rune, rune], i int) (rune, bool) {
+	if i < 0 {
+		return 0, false
+	}
+	for lo, hi := range seq {
+		if n := int(hi-lo) + 1; i >= n {
+			i -= n
+			continue
+		}
+		return lo + rune(i), true
+	}
+	return 0, false
+}
@@ -0,0 +1,208 @@
+package runes
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"unicode"
+
+	"github.com/diegommm/runes/util"
+)
+
+var _ = []Indexed{
+	Union[MinMaxSet]{},
+	SortedUnion[MinMaxSet]{},
+	LinearSlice[uint8]{},
+	BinarySlice[uint16]{},
+	RangeSlice[uint8]{},
+	StridedRanges[uint8]{},
+	Interval[uint32]{},
+	Uniform[rune]{},
+	Bitmap(""),
+	TwoLevel{},
+	SparseBitmap{},
+	Roaring{},
+	MixedUnion{},
+}
+
+func TestIndexed(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		set   Indexed
+		runes []rune
+	}{
+		{LinearSlice[uint8](nil), nil},
+		{LinearSlice[uint8]{1, 2, 3, 5}, []rune{1, 2, 3, 5}},
+		{BinarySlice[rune]{1, 0x10000, 0x10001}, []rune{1, 0x10000, 0x10001}},
+		{RangeSlice[uint8](nil), nil},
+		{RangeSlice[uint8]{{1, 2}, {3, 3}, {5, 6}, {0xff, 0xff}}, []rune{1, 2, 3, 5, 6, 0xff}},
+		{RangeSlice[rune]{{0, 0}, {0x10000, 0x10002}}, []rune{0, 0x10000, 0x10001, 0x10002}},
+		{StridedRanges[uint8](nil), nil},
+		{StridedRanges[uint8]{{1, 3, 1}, {5, 9, 2}, {0xff, 0xff, 1}}, []rune{1, 2, 3, 5, 7, 9, 0xff}},
+		{StridedRanges[rune]{{0, 0, 1}, {0x10000, 0x10004, 2}}, []rune{0, 0x10000, 0x10002, 0x10004}},
+		{Interval[uint8]{'a', 'c'}, []rune{'a', 'b', 'c'}},
+		{Interval[uint8]{'c', 'a'}, nil},
+		{Uniform[uint8]{}, nil},
+		{Uniform[uint8]{253, 255, 1}, []rune{253, 254, 255}},
+		{Uniform[uint8]{3, 31, 7}, []rune{3, 10, 17, 24, 31}},
+		{NewBitmap(nil), nil},
+		{NewBitmap([]rune{1, 7, 8, 9, 17}), []rune{1, 7, 8, 9, 17}},
+		{NewBitmap(slices.Collect(util.Seq(3, 300, 3))), slices.Collect(util.Seq(3, 300, 3))},
+		{NewTwoLevel(nil), nil},
+		{NewTwoLevel([]rune{1, 63, 64, 200, 0x10000}), []rune{1, 63, 64, 200, 0x10000}},
+		{NewTwoLevel(slices.Collect(util.Seq(3, 300, 3))), slices.Collect(util.Seq(3, 300, 3))},
+		{NewSparseBitmap(nil), nil},
+		{NewSparseBitmap([]rune{1, 63, 64, 200, 0x10000}), []rune{1, 63, 64, 200, 0x10000}},
+		{NewSparseBitmap(slices.Collect(util.Seq(3, 300, 3))), slices.Collect(util.Seq(3, 300, 3))},
+		{NewRoaring(nil), nil},
+		{NewRoaring(slices.Collect(util.Concat(runes(1, 3, 99), util.Seq(0x1000, 0x1fff, 1), util.Seq(0x2000, 0x2fff, 3), util.Seq(0x10000, 0x10100, 1)))), slices.Collect(util.Concat(runes(1, 3, 99), util.Seq(0x1000, 0x1fff, 1), util.Seq(0x2000, 0x2fff, 3), util.Seq(0x10000, 0x10100, 1)))},
+		{Union[MinMaxSet](nil), nil},
+		{
+			set:   Union[MinMaxSet]{Interval[uint8]{1, 3}, Interval[uint8]{4, 5}, LinearSlice[uint8]{7, 9}},
+			runes: []rune{1, 2, 3, 4, 5, 7, 9},
+		},
+		{
+			set:   Union[MinMaxSet]{Interval[uint8]{1, 3}, LinearSlice[uint8]{2, 9}, Interval[uint8]{5, 8}},
+			runes: []rune{1, 2, 3, 5, 6, 7, 8, 9},
+		},
+		{
+			set: Union[MinMaxSet]{minMaxFunc{
+				ContainsFunc: func(r rune) bool { return r%2 == 0 },
+				min:          2,
+				max:          6,
+			}},
+			runes: []rune{2, 4, 6},
+		},
+		{MixedUnion{}, nil},
+		{mixedUnion, mixedUnionRunes},
+		{NewMixedUnion(slices.Collect(util.RangeTableIter(util.Tables["L"]))), slices.Collect(util.RangeTableIter(util.Tables["L"]))},
+		{withoutRanks(NewTwoLevel([]rune{1, 63, 64, 200, 0x10000})), []rune{1, 63, 64, 200, 0x10000}},
+		{withoutRanks(NewSparseBitmap([]rune{1, 63, 64, 200, 0x10000})), []rune{1, 63, 64, 200, 0x10000}},
+		{withoutRanks(NewRoaring(slices.Collect(util.Seq(0x1000, 0x2ffe, 3)))), slices.Collect(util.Seq(0x1000, 0x2ffe, 3))},
+		{withoutRanks(mixedUnion), mixedUnionRunes},
+		{MustSortedUnion[MinMaxSet](), nil},
+		{
+			set:   MustSortedUnion[MinMaxSet](Interval[uint8]{1, 3}, LinearSlice[uint16]{7, 0x100}, NewBitmap([]rune{0x200, 0x10000})),
+			runes: []rune{1, 2, 3, 7, 0x100, 0x200, 0x10000},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			var hi rune = 1
+			if len(tc.runes) > 0 {
+				hi = tc.runes[len(tc.runes)-1] + 2
+			}
+			testIndexed(t, tc.set, tc.runes, slices.Collect(util.Seq(-1, hi, 1)))
+		})
+	}
+}
+
+func TestIndexedCompile(t *testing.T) {
+	t.Parallel()
+	tables := []*unicode.RangeTable{
+		unicode.White_Space,
+		unicode.Greek,
+		unicode.Upper,
+		unicode.Letter,
+	}
+
+	for i, rt := range tables {
+		rs := slices.Collect(util.RangeTableIter(rt))
+		var probes []rune
+		for j := 0; j < len(rs); j += 97 {
+			probes = append(probes, rs[j]-1, rs[j], rs[j]+1)
+		}
+		for _, stepCost := range []int{0, defaultStepCost, 1000} {
+			t.Run(fmt.Sprintf("index=%v,StepCost=%v", i, stepCost), func(t *testing.T) {
+				t.Parallel()
+				testIndexed(t, Compile(rs, StepCost(stepCost)).(Indexed), rs, probes)
+			})
+		}
+	}
+}
+
+// withoutRanks returns `s` with its optional cumulative counts removed, so
+// they are computed on each call.
+func withoutRanks(s Indexed) Indexed {
+	return mapRanks(s, func([]uint32) []uint32 { return nil })
+}
+
+// mapRanks returns `s` with its optional cumulative counts replaced by the
+// result of `f`, which is given a copy of them.
+func mapRanks(s Indexed, f func([]uint32) []uint32) Indexed {
+	switch x := s.(type) {
+	case TwoLevel:
+		x.Ranks = f(slices.Clone(x.Ranks))
+		return x
+	case SparseBitmap:
+		x.Ranks = f(slices.Clone(x.Ranks))
+		return x
+	case Roaring:
+		x.Ranks = f(slices.Clone(x.Ranks))
+		return x
+	case MixedUnion:
+		x.Ranks = f(slices.Clone(x.Ranks))
+		return x
+	}
+	return s
+}
+
+func TestIndexedInvalidRanks(t *testing.T) {
+	t.Parallel()
+	rs := []rune{1, 63, 64, 200, 0x10000}
+	sets := []Indexed{NewTwoLevel(rs), NewSparseBitmap(rs), NewRoaring(rs), NewMixedUnion(rs)}
+	corrupt := []func([]uint32) []uint32{
+		func(ranks []uint32) []uint32 { slices.Reverse(ranks); return ranks },
+		func(ranks []uint32) []uint32 {
+			for i := range ranks {
+				ranks[i] += 5
+			}
+			return ranks
+		},
+		func(ranks []uint32) []uint32 {
+			clear(ranks)
+			ranks[len(ranks)-1] = 100
+			return ranks
+		},
+	}
+
+	for i, s := range sets {
+		// Ranks of the wrong length are computed again
+		testIndexed(t, mapRanks(s, func(ranks []uint32) []uint32 { return ranks[:1] }), rs, rs)
+		for j, f := range corrupt {
+			t.Run(fmt.Sprintf("index=%v,corrupt=%v", i, j), func(t *testing.T) {
+				t.Parallel()
+				// the results are wrong, but must not panic
+				x := mapRanks(s, f)
+				for k := -1; k <= 100; k++ {
+					x.Select(k)
+				}
+				for _, r := range rs {
+					x.Rank(r)
+				}
+			})
+		}
+	}
+}
+
+// testIndexed checks the methods of `s`, which has exactly the runes `rs`,
+// ranking each of the `probes`.
+func testIndexed(t *testing.T, s Indexed, rs, probes []rune) {
+	t.Helper()
+	util.MustEqual(t, len(rs), s.Len(), "Len")
+	for _, r := range probes {
+		expected, _ := slices.BinarySearch(rs, r)
+		util.MustEqual(t, expected, s.Rank(r), "Rank(0x%x)", r)
+	}
+	for i := -1; i <= len(rs); i++ {
+		var expected rune
+		if i >= 0 && i < len(rs) {
+			expected = rs[i]
+		}
+		got, ok := s.Select(i)
+		util.MustEqual(t, i >= 0 && i < len(rs), ok, "Select(%v) ok", i)
+		util.MustEqual(t, expected, got, "Select(%v)", i)
+	}
+}
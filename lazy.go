@@ -0,0 +1,141 @@
+package runes
+
+import "unicode/utf8"
+
+// And returns a lazy [MinMaxSet] with the runes that are in both `a` and `b`.
+func And[A, B MinMaxSet](a A, b B) AndSet[A, B] {
+	x := AndSet[A, B]{a: a, b: b, min: MaxUint32, max: MaxUint32}
+	if lo, hi := max(a.Min(), b.Min()), min(a.Max(), b.Max()); lo <= hi {
+		x.min, x.max = lo, hi
+	}
+	return x
+}
+
+// AndSet is the lazy intersection of two sets. Its Min and Max are bounds
+// computed from those of its operands, so they are not necessarily members.
+type AndSet[A, B MinMaxSet] struct {
+	a        A
+	b        B
+	min, max uint32
+}
+
+func (x AndSet[A, B]) Contains(r rune) bool {
+	return uint32(r) >= x.min && uint32(r) <= x.max &&
+		x.a.Contains(r) && x.b.Contains(r)
+}
+
+func (x AndSet[A, B]) Min() uint32 {
+	return x.min
+}
+
+func (x AndSet[A, B]) Max() uint32 {
+	return x.max
+}
+
+// Or returns a lazy [MinMaxSet] with the runes that are in either `a` or `b`.
+func Or[A, B MinMaxSet](a A, b B) OrSet[A, B] {
+	x := OrSet[A, B]{a: a, b: b, min: min(a.Min(), b.Min())}
+	switch {
+	case a.Min() == MaxUint32:
+		x.max = b.Max()
+	case b.Min() == MaxUint32:
+		x.max = a.Max()
+	default:
+		x.max = max(a.Max(), b.Max())
+	}
+	return x
+}
+
+// OrSet is the lazy union of two sets.
+type OrSet[A, B MinMaxSet] struct {
+	a        A
+	b        B
+	min, max uint32
+}
+
+func (x OrSet[A, B]) Contains(r rune) bool {
+	return uint32(r) >= x.min && uint32(r) <= x.max &&
+		(x.a.Contains(r) || x.b.Contains(r))
+}
+
+func (x OrSet[A, B]) Min() uint32 {
+	return x.min
+}
+
+func (x OrSet[A, B]) Max() uint32 {
+	return x.max
+}
+
+// Not returns a lazy [MinMaxSet] with the runes in [0, utf8.MaxRune] that are
+// not in `s`.
+func Not[S MinMaxSet](s S) NotSet[S] {
+	lo, hi := rune(0), rune(utf8.MaxRune)
+	if e, ok := any(s).(Enumerable); ok {
+		// skip the ranges of `s` at both ends
+		if s.Min() == 0 {
+			for _, rhi := range e.Ranges() {
+				lo = rhi + 1
+				break
+			}
+		}
+		if m := s.Max(); m != MaxUint32 && m >= utf8.MaxRune && lo <= hi && s.Contains(utf8.MaxRune) {
+			hi = topRangeLo(e, lo) - 1
+		}
+	}
+	x := NotSet[S]{s: s, min: MaxUint32, max: MaxUint32}
+	if lo <= hi {
+		x.min, x.max = uint32(lo), uint32(hi)
+	}
+	return x
+}
+
+// topRangeLo returns the first rune of the range of `s` that ends at or after
+// utf8.MaxRune, which must be in `s`, and starts at or after `lo`. If `s` is
+// [Indexed], it is found with a binary search over the number of runes of `s`
+// up to utf8.MaxRune. Otherwise, all the ranges of `s` are iterated.
+func topRangeLo(s Enumerable, lo rune) rune {
+	if x, ok := s.(Indexed); ok {
+		// the runes from h to utf8.MaxRune are all in `s` only if h is not
+		// before the first rune of the range
+		top := x.Rank(utf8.MaxRune + 1)
+		i, j := lo, rune(utf8.MaxRune)
+		for i < j {
+			h := i + (j-i)/2
+			if top-x.Rank(h) == int(utf8.MaxRune-h)+1 {
+				j = h
+			} else {
+				i = h + 1
+			}
+		}
+		return i
+	}
+	res := lo
+	for rlo, rhi := range s.Ranges() {
+		if rhi >= utf8.MaxRune {
+			res = max(rlo, lo)
+			break
+		}
+	}
+	return res
+}
+
+// NotSet is the lazy complement of a set. Its Min and Max skip the ranges at
+// both ends of the set only if it is [Enumerable]. Otherwise, they are zero and
+// utf8.MaxRune, so they are not necessarily members.
+type NotSet[S MinMaxSet] struct {
+	s        S
+	min, max uint32
+}
+
+func (x NotSet[S]) Contains(r rune) bool {
+	// an empty NotSet has both bounds set to MaxUint32, which is uint32(-1)
+	return r >= 0 && uint32(r) >= x.min && uint32(r) <= x.max && !x.s.Contains(r)
+}
+
+func (x NotSet[S]) Min() uint32 {
+	return x.min
+}
+
+func (x NotSet[S]) Max() uint32 {
+	return x.max
+}
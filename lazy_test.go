@@ -0,0 +1,86 @@
+package runes
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestLazy(t *testing.T) {
+	t.Parallel()
+	lower := Interval[uint8]{'a', 'z'}
+	vowels := NewBitmap([]rune{'a', 'e', 'i', 'o', 'u'})
+	digits := Interval[uint8]{'0', '9'}
+	empty := LinearSlice[uint8](nil)
+	all := Interval[rune]{0, utf8.MaxRune}
+	isLower := func(r rune) bool { return r >= 'a' && r <= 'z' }
+	isVowel := func(r rune) bool { return r == 'a' || r == 'e' || r == 'i' || r == 'o' || r == 'u' }
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	isValid := func(r rune) bool { return r >= 0 && r <= utf8.MaxRune }
+
+	testCases := []struct {
+		set      MinMaxSet
+		min, max uint32
+		expected func(rune) bool
+	}{
+		{And(lower, vowels), 'a', 'u', isVowel},
+		{And(lower, digits), MaxUint32, MaxUint32, func(rune) bool { return false }},
+		{And(lower, empty), MaxUint32, MaxUint32, func(rune) bool { return false }},
+		{Or(digits, vowels), '0', 'u', func(r rune) bool { return isDigit(r) || isVowel(r) }},
+		{Or(empty, vowels), 'a', 'u', isVowel},
+		{Or(vowels, empty), 'a', 'u', isVowel},
+		{Or(empty, empty), MaxUint32, MaxUint32, func(rune) bool { return false }},
+		{Not(lower), 0, utf8.MaxRune, func(r rune) bool { return isValid(r) && !isLower(r) }},
+		{Not(Interval[uint8]{0, 9}), 10, utf8.MaxRune, func(r rune) bool { return isValid(r) && r > 9 }},
+		{Not(Interval[rune]{10, utf8.MaxRune}), 0, 9, func(r rune) bool { return r >= 0 && r < 10 }},
+		{Not(Interval[rune]{1, math.MaxInt32}), 0, 0, func(r rune) bool { return r == 0 }},
+		{Not(StridedRanges[rune]{{0, 4, 2}, {6, utf8.MaxRune, 1}}), 1, 5, func(r rune) bool { return r == 1 || r == 3 || r == 5 }},
+		{Not(empty), 0, utf8.MaxRune, isValid},
+		{Not(all), MaxUint32, MaxUint32, func(rune) bool { return false }},
+		{Not(RangeSlice[rune]{{0, 5}, {7, 9}, {20, utf8.MaxRune}}), 6, 19, func(r rune) bool {
+			return r == 6 || r >= 10 && r < 20
+		}},
+		{Not(Not(lower)), 0, utf8.MaxRune, isLower},
+		{
+			set: And(lower, Not(vowels)),
+			min: 'a',
+			max: 'z',
+			expected: func(r rune) bool {
+				return isLower(r) && !isVowel(r)
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			util.Equal(t, tc.min, tc.set.Min(), "Min")
+			util.Equal(t, tc.max, tc.set.Max(), "Max")
+			testPredicateEquivalence(t, tc.set, tc.expected)
+		})
+	}
+}
+
+func TestLazyAllocs(t *testing.T) {
+	s := Or(Interval[uint8]{'a', 'z'}, And(NewBitmap([]rune{'0', '5', '9'}), Not(Interval[uint8]{'5', '5'})))
+	allocs := testing.AllocsPerRun(100, func() {
+		s.Contains('0')
+		s.Contains('5')
+		s.Contains('q')
+	})
+	util.Equal(t, 0.0, allocs, "expected no allocations")
+}
+
+func TestNotCalls(t *testing.T) {
+	t.Parallel()
+	var calls int
+	all := util.ContainsFunc(func(r rune) bool {
+		calls++
+		return true
+	})
+	Not(minMaxFunc{all, 0, utf8.MaxRune})
+	util.Equal(t, 0, calls, "Contains calls")
+}
@@ -0,0 +1,444 @@
+package runes
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Lookup returns the set for the given Unicode property, which can be:
+//   - A General Category, by its short or long name, like "Lu" or
+//     "Uppercase_Letter".
+//   - A script, by its name or its ISO 15924 code, like "Greek" or "Grek".
+//   - A binary property, by its long or short name, like "White_Space" or
+//     "WSpace".
+//   - A property and a value separated by "=", where the property is one of
+//     "General_Category" ("gc") or "Script" ("sc"), like "gc=Lu" or
+//     "Script=Greek".
+//   - One of the special names "Any", "ASCII" or "Assigned" of Unicode
+//     Technical Standard #18.
+//   - One of the derived properties "Alphabetic" ("Alpha"), "Lowercase"
+//     ("Lower") or "Uppercase" ("Upper"), or of the POSIX compatible
+//     properties "alnum", "blank", "graph", "print", "word" or "xdigit", as
+//     defined in Annex C of the same standard. The other POSIX names are
+//     aliases of General Categories or binary properties, like "punct" or
+//     "space".
+//
+// Names are matched ignoring case, whitespace, "_" and "-", so "white space"
+// and "WHITE-SPACE" are the same as "White_Space". Names without a property
+// are first looked up as General Categories, then as scripts and then as
+// binary properties. The tables come from package unicode, and each set is
+// built with [FromRangeTable] the first time it is looked up, and shared from
+// then on. The boolean result reports whether the name was found.
+func Lookup(name string) (MinMaxSet, bool) {
+	reg := loadRegistry()
+	var p *property
+	if key, value, ok := strings.Cut(name, "="); ok {
+		if idx := reg.byName[looseName(key)]; idx != nil {
+			p = idx[looseName(value)]
+		}
+	} else {
+		key := looseName(name)
+		for _, idx := range reg.bare {
+			if p = idx[key]; p != nil {
+				break
+			}
+		}
+	}
+	if p == nil {
+		return nil, false
+	}
+	return p.set(), true
+}
+
+// property is an entry of the registry, which builds its set once.
+type property struct {
+	set func() MinMaxSet
+}
+
+func newProperty(f func() MinMaxSet) *property {
+	return &property{set: sync.OnceValue(f)}
+}
+
+func tableProperty(rt *unicode.RangeTable) *property {
+	return newProperty(func() MinMaxSet { return FromRangeTable(rt) })
+}
+
+// registry holds the indexes of properties by their loose names.
+type registry struct {
+	// byName has the index of values of each enumerated property
+	byName map[string]map[string]*property
+	// bare has the indexes used for names without a property, in order
+	bare []map[string]*property
+}
+
+var loadRegistry = sync.OnceValue(func() registry {
+	special := specialIndex()
+	gc := aliasIndex(unicode.Categories, categoryAliases)
+	sc := aliasIndex(unicode.Scripts, scriptAliases)
+	binary := aliasIndex(unicode.Properties, propertyAliases)
+	return registry{
+		byName: map[string]map[string]*property{
+			"gc":              gc,
+			"generalcategory": gc,
+			"sc":              sc,
+			"script":          sc,
+		},
+		bare: []map[string]*property{special, gc, sc, binary},
+	}
+})
+
+// specialIndex returns the index of the special names of Unicode Technical
+// Standard #18, the derived properties that can be computed from the tables of
+// package unicode, and the POSIX compatible properties of Annex C of the
+// standard.
+func specialIndex() map[string]*property {
+	tab := &unicode.RangeTable{R16: []unicode.Range16{{'\t', '\t', 1}}}
+	// assigned has the tables of the assigned runes. It does not use the
+	// complement of Cn, which older versions of package unicode do not have
+	assigned := []*unicode.RangeTable{
+		unicode.L, unicode.M, unicode.N, unicode.P, unicode.S,
+		unicode.Z, unicode.Cc, unicode.Cf, unicode.Co, unicode.Cs,
+	}
+	alpha := []*unicode.RangeTable{unicode.L, unicode.Nl, unicode.Other_Alphabetic}
+	graph := func() []rune {
+		return combineBounds(tablesBounds(assigned...),
+			tablesBounds(unicode.White_Space, unicode.Cc, unicode.Cs), andNot)
+	}
+
+	idx := map[string]*property{
+		"any": newProperty(func() MinMaxSet {
+			return Interval[rune]{0, utf8.MaxRune}
+		}),
+		"ascii": newProperty(func() MinMaxSet {
+			return Interval[uint8]{0, unicode.MaxASCII}
+		}),
+		"assigned":   tablesProperty(assigned...),
+		"alphabetic": tablesProperty(alpha...),
+		"lowercase":  tablesProperty(unicode.Ll, unicode.Other_Lowercase),
+		"uppercase":  tablesProperty(unicode.Lu, unicode.Other_Uppercase),
+		"alnum":      tablesProperty(append(alpha, unicode.Nd)...),
+		"xdigit":     tablesProperty(unicode.Nd, unicode.Hex_Digit),
+		"blank":      tablesProperty(unicode.Zs, tab),
+		"word": tablesProperty(append(alpha, unicode.M, unicode.Nd,
+			unicode.Pc, unicode.Join_Control)...),
+		"graph": newProperty(func() MinMaxSet {
+			return compileBounds(graph())
+		}),
+		"print": newProperty(func() MinMaxSet {
+			// the union of graph and blank, without Cc
+			return compileBounds(combineBounds(graph(), tablesBounds(unicode.Zs), or))
+		}),
+	}
+	idx["alpha"] = idx["alphabetic"]
+	idx["lower"] = idx["lowercase"]
+	idx["upper"] = idx["uppercase"]
+	return idx
+}
+
+// tablesProperty returns a property with the union of the given tables.
+func tablesProperty(rts ...*unicode.RangeTable) *property {
+	if len(rts) == 1 {
+		return tableProperty(rts[0])
+	}
+	return newProperty(func() MinMaxSet {
+		return compileBounds(tablesBounds(rts...))
+	})
+}
+
+// tablesBounds returns the bounds of the union of the given tables.
+func tablesBounds(rts ...*unicode.RangeTable) []rune {
+	var b []rune
+	for _, rt := range rts {
+		b = combineBounds(b, setBounds(FromRangeTable(rt)), or)
+	}
+	return b
+}
+
+// aliasIndex returns an index of the given tables by the loose form of their
+// names and aliases.
+func aliasIndex(tables map[string]*unicode.RangeTable, aliases map[string][]string) map[string]*property {
+	idx := make(map[string]*property, len(tables))
+	for name, rt := range tables {
+		p := tableProperty(rt)
+		idx[looseName(name)] = p
+		for _, alias := range aliases[name] {
+			idx[looseName(alias)] = p
+		}
+	}
+	return idx
+}
+
+// looseName returns `name` in lower case and without whitespace, "_" or "-",
+// following the loose matching rule UAX44-LM3 of Unicode Standard Annex #44,
+// except for the "is" prefix.
+func looseName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '_', '-':
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// categoryAliases has the long names and other aliases of the General
+// Categories in package unicode, from PropertyValueAliases.txt.
+var categoryAliases = map[string][]string{
+	"C":  {"Other"},
+	"Cc": {"Control", "cntrl"},
+	"Cf": {"Format"},
+	"Cn": {"Unassigned"},
+	"Co": {"Private_Use"},
+	"Cs": {"Surrogate"},
+	"L":  {"Letter"},
+	"LC": {"Cased_Letter"},
+	"Ll": {"Lowercase_Letter"},
+	"Lm": {"Modifier_Letter"},
+	"Lo": {"Other_Letter"},
+	"Lt": {"Titlecase_Letter"},
+	"Lu": {"Uppercase_Letter"},
+	"M":  {"Mark", "Combining_Mark"},
+	"Mc": {"Spacing_Mark"},
+	"Me": {"Enclosing_Mark"},
+	"Mn": {"Nonspacing_Mark"},
+	"N":  {"Number"},
+	"Nd": {"Decimal_Number", "digit"},
+	"Nl": {"Letter_Number"},
+	"No": {"Other_Number"},
+	"P":  {"Punctuation", "punct"},
+	"Pc": {"Connector_Punctuation"},
+	"Pd": {"Dash_Punctuation"},
+	"Pe": {"Close_Punctuation"},
+	"Pf": {"Final_Punctuation"},
+	"Pi": {"Initial_Punctuation"},
+	"Po": {"Other_Punctuation"},
+	"Ps": {"Open_Punctuation"},
+	"S":  {"Symbol"},
+	"Sc": {"Currency_Symbol"},
+	"Sk": {"Modifier_Symbol"},
+	"Sm": {"Math_Symbol"},
+	"So": {"Other_Symbol"},
+	"Z":  {"Separator"},
+	"Zl": {"Line_Separator"},
+	"Zp": {"Paragraph_Separator"},
+	"Zs": {"Space_Separator"},
+}
+
+// scriptAliases has the ISO 15924 codes and other aliases of the scripts in
+// package unicode, from PropertyValueAliases.txt.
+var scriptAliases = map[string][]string{
+	"Adlam":                  {"Adlm"},
+	"Ahom":                   {"Ahom"},
+	"Anatolian_Hieroglyphs":  {"Hluw"},
+	"Arabic":                 {"Arab"},
+	"Armenian":               {"Armn"},
+	"Avestan":                {"Avst"},
+	"Balinese":               {"Bali"},
+	"Bamum":                  {"Bamu"},
+	"Bassa_Vah":              {"Bass"},
+	"Batak":                  {"Batk"},
+	"Bengali":                {"Beng"},
+	"Beria_Erfe":             {"Berf"},
+	"Bhaiksuki":              {"Bhks"},
+	"Bopomofo":               {"Bopo"},
+	"Brahmi":                 {"Brah"},
+	"Braille":                {"Brai"},
+	"Buginese":               {"Bugi"},
+	"Buhid":                  {"Buhd"},
+	"Canadian_Aboriginal":    {"Cans"},
+	"Carian":                 {"Cari"},
+	"Caucasian_Albanian":     {"Aghb"},
+	"Chakma":                 {"Cakm"},
+	"Cham":                   {"Cham"},
+	"Cherokee":               {"Cher"},
+	"Chorasmian":             {"Chrs"},
+	"Common":                 {"Zyyy"},
+	"Coptic":                 {"Copt", "Qaac"},
+	"Cuneiform":              {"Xsux"},
+	"Cypriot":                {"Cprt"},
+	"Cypro_Minoan":           {"Cpmn"},
+	"Cyrillic":               {"Cyrl"},
+	"Deseret":                {"Dsrt"},
+	"Devanagari":             {"Deva"},
+	"Dives_Akuru":            {"Diak"},
+	"Dogra":                  {"Dogr"},
+	"Duployan":               {"Dupl"},
+	"Egyptian_Hieroglyphs":   {"Egyp"},
+	"Elbasan":                {"Elba"},
+	"Elymaic":                {"Elym"},
+	"Ethiopic":               {"Ethi"},
+	"Garay":                  {"Gara"},
+	"Georgian":               {"Geor"},
+	"Glagolitic":             {"Glag"},
+	"Gothic":                 {"Goth"},
+	"Grantha":                {"Gran"},
+	"Greek":                  {"Grek"},
+	"Gujarati":               {"Gujr"},
+	"Gunjala_Gondi":          {"Gong"},
+	"Gurmukhi":               {"Guru"},
+	"Gurung_Khema":           {"Gukh"},
+	"Han":                    {"Hani"},
+	"Hangul":                 {"Hang"},
+	"Hanifi_Rohingya":        {"Rohg"},
+	"Hanunoo":                {"Hano"},
+	"Hatran":                 {"Hatr"},
+	"Hebrew":                 {"Hebr"},
+	"Hiragana":               {"Hira"},
+	"Imperial_Aramaic":       {"Armi"},
+	"Inherited":              {"Zinh", "Qaai"},
+	"Inscriptional_Pahlavi":  {"Phli"},
+	"Inscriptional_Parthian": {"Prti"},
+	"Javanese":               {"Java"},
+	"Kaithi":                 {"Kthi"},
+	"Kannada":                {"Knda"},
+	"Katakana":               {"Kana"},
+	"Kawi":                   {"Kawi"},
+	"Kayah_Li":               {"Kali"},
+	"Kharoshthi":             {"Khar"},
+	"Khitan_Small_Script":    {"Kits"},
+	"Khmer":                  {"Khmr"},
+	"Khojki":                 {"Khoj"},
+	"Khudawadi":              {"Sind"},
+	"Kirat_Rai":              {"Krai"},
+	"Lao":                    {"Laoo"},
+	"Latin":                  {"Latn"},
+	"Lepcha":                 {"Lepc"},
+	"Limbu":                  {"Limb"},
+	"Linear_A":               {"Lina"},
+	"Linear_B":               {"Linb"},
+	"Lisu":                   {"Lisu"},
+	"Lycian":                 {"Lyci"},
+	"Lydian":                 {"Lydi"},
+	"Mahajani":               {"Mahj"},
+	"Makasar":                {"Maka"},
+	"Malayalam":              {"Mlym"},
+	"Mandaic":                {"Mand"},
+	"Manichaean":             {"Mani"},
+	"Marchen":                {"Marc"},
+	"Masaram_Gondi":          {"Gonm"},
+	"Medefaidrin":            {"Medf"},
+	"Meetei_Mayek":           {"Mtei"},
+	"Mende_Kikakui":          {"Mend"},
+	"Meroitic_Cursive":       {"Merc"},
+	"Meroitic_Hieroglyphs":   {"Mero"},
+	"Miao":                   {"Plrd"},
+	"Modi":                   {"Modi"},
+	"Mongolian":              {"Mong"},
+	"Mro":                    {"Mroo"},
+	"Multani":                {"Mult"},
+	"Myanmar":                {"Mymr"},
+	"Nabataean":              {"Nbat"},
+	"Nag_Mundari":            {"Nagm"},
+	"Nandinagari":            {"Nand"},
+	"New_Tai_Lue":            {"Talu"},
+	"Newa":                   {"Newa"},
+	"Nko":                    {"Nkoo"},
+	"Nushu":                  {"Nshu"},
+	"Nyiakeng_Puachue_Hmong": {"Hmnp"},
+	"Ogham":                  {"Ogam"},
+	"Ol_Chiki":               {"Olck"},
+	"Ol_Onal":                {"Onao"},
+	"Old_Hungarian":          {"Hung"},
+	"Old_Italic":             {"Ital"},
+	"Old_North_Arabian":      {"Narb"},
+	"Old_Permic":             {"Perm"},
+	"Old_Persian":            {"Xpeo"},
+	"Old_Sogdian":            {"Sogo"},
+	"Old_South_Arabian":      {"Sarb"},
+	"Old_Turkic":             {"Orkh"},
+	"Old_Uyghur":             {"Ougr"},
+	"Oriya":                  {"Orya"},
+	"Osage":                  {"Osge"},
+	"Osmanya":                {"Osma"},
+	"Pahawh_Hmong":           {"Hmng"},
+	"Palmyrene":              {"Palm"},
+	"Pau_Cin_Hau":            {"Pauc"},
+	"Phags_Pa":               {"Phag"},
+	"Phoenician":             {"Phnx"},
+	"Psalter_Pahlavi":        {"Phlp"},
+	"Rejang":                 {"Rjng"},
+	"Runic":                  {"Runr"},
+	"Samaritan":              {"Samr"},
+	"Saurashtra":             {"Saur"},
+	"Sharada":                {"Shrd"},
+	"Shavian":                {"Shaw"},
+	"Siddham":                {"Sidd"},
+	"Sidetic":                {"Sidt"},
+	"SignWriting":            {"Sgnw"},
+	"Sinhala":                {"Sinh"},
+	"Sogdian":                {"Sogd"},
+	"Sora_Sompeng":           {"Sora"},
+	"Soyombo":                {"Soyo"},
+	"Sundanese":              {"Sund"},
+	"Sunuwar":                {"Sunu"},
+	"Syloti_Nagri":           {"Sylo"},
+	"Syriac":                 {"Syrc"},
+	"Tagalog":                {"Tglg"},
+	"Tagbanwa":               {"Tagb"},
+	"Tai_Le":                 {"Tale"},
+	"Tai_Tham":               {"Lana"},
+	"Tai_Viet":               {"Tavt"},
+	"Tai_Yo":                 {"Tayo"},
+	"Takri":                  {"Takr"},
+	"Tamil":                  {"Taml"},
+	"Tangsa":                 {"Tnsa"},
+	"Tangut":                 {"Tang"},
+	"Telugu":                 {"Telu"},
+	"Thaana":                 {"Thaa"},
+	"Thai":                   {"Thai"},
+	"Tibetan":                {"Tibt"},
+	"Tifinagh":               {"Tfng"},
+	"Tirhuta":                {"Tirh"},
+	"Todhri":                 {"Todr"},
+	"Tolong_Siki":            {"Tols"},
+	"Toto":                   {"Toto"},
+	"Tulu_Tigalari":          {"Tutg"},
+	"Ugaritic":               {"Ugar"},
+	"Vai":                    {"Vaii"},
+	"Vithkuqi":               {"Vith"},
+	"Wancho":                 {"Wcho"},
+	"Warang_Citi":            {"Wara"},
+	"Yezidi":                 {"Yezi"},
+	"Yi":                     {"Yiii"},
+	"Zanabazar_Square":       {"Zanb"},
+}
+
+// propertyAliases has the short names of the binary properties in package
+// unicode, from PropertyAliases.txt. STerm is missing because package unicode
+// already has it.
+var propertyAliases = map[string][]string{
+	"ASCII_Hex_Digit":                    {"AHex"},
+	"Bidi_Control":                       {"Bidi_C"},
+	"Deprecated":                         {"Dep"},
+	"Diacritic":                          {"Dia"},
+	"Extender":                           {"Ext"},
+	"Hex_Digit":                          {"Hex"},
+	"IDS_Binary_Operator":                {"IDSB"},
+	"IDS_Trinary_Operator":               {"IDST"},
+	"IDS_Unary_Operator":                 {"IDSU"},
+	"Ideographic":                        {"Ideo"},
+	"Join_Control":                       {"Join_C"},
+	"Logical_Order_Exception":            {"LOE"},
+	"Modifier_Combining_Mark":            {"MCM"},
+	"Noncharacter_Code_Point":            {"NChar"},
+	"Other_Alphabetic":                   {"OAlpha"},
+	"Other_Default_Ignorable_Code_Point": {"ODI"},
+	"Other_Grapheme_Extend":              {"OGr_Ext"},
+	"Other_ID_Continue":                  {"OIDC"},
+	"Other_ID_Start":                     {"OIDS"},
+	"Other_Lowercase":                    {"OLower"},
+	"Other_Math":                         {"OMath"},
+	"Other_Uppercase":                    {"OUpper"},
+	"Pattern_Syntax":                     {"Pat_Syn"},
+	"Pattern_White_Space":                {"Pat_WS"},
+	"Prepended_Concatenation_Mark":       {"PCM"},
+	"Quotation_Mark":                     {"QMark"},
+	"Regional_Indicator":                 {"RI"},
+	"Soft_Dotted":                        {"SD"},
+	"Terminal_Punctuation":               {"Term"},
+	"Unified_Ideograph":                  {"UIdeo"},
+	"Variation_Selector":                 {"VS"},
+	"White_Space":                        {"WSpace", "space"},
+}
@@ -0,0 +1,138 @@
+package runes
+
+import (
+	"fmt"
+	"testing"
+	"unicode"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		expected *unicode.RangeTable
+	}{
+		{"L", unicode.L},
+		{"Letter", unicode.L},
+		{"General_Category=Letter", unicode.L},
+		{"gc=Lu", unicode.Lu},
+		{"GC = uppercase letter", unicode.Lu},
+		{"lowercase-letter", unicode.Ll},
+		{"Other", unicode.C},
+		{"Greek", unicode.Greek},
+		{"Grek", unicode.Greek},
+		{"sc=Grek", unicode.Greek},
+		{"Script=greek", unicode.Greek},
+		{"Zyyy", unicode.Common},
+		{"White_Space", unicode.White_Space},
+		{"WSpace", unicode.White_Space},
+		{"whitespace", unicode.White_Space},
+		{"WHITE SPACE", unicode.White_Space},
+		{"AHex", unicode.ASCII_Hex_Digit},
+		{"Dash", unicode.Dash},
+		{"Dash_Punctuation", unicode.Pd},
+		{"any", &unicode.RangeTable{R16: []unicode.Range16{{0, 0xffff, 1}},
+			R32: []unicode.Range32{{0x10000, unicode.MaxRune, 1}}}},
+		{"ASCII", &unicode.RangeTable{R16: []unicode.Range16{{0, 0x7f, 1}}}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			s, ok := Lookup(tc.name)
+			util.MustEqual(t, true, ok, "%q not found", tc.name)
+			testRangeTableEquivalence(t, s, tc.expected)
+		})
+	}
+}
+
+func TestLookupDerived(t *testing.T) {
+	t.Parallel()
+	in := func(rts ...*unicode.RangeTable) func(rune) bool {
+		return func(r rune) bool { return unicode.In(r, rts...) }
+	}
+	assigned := in(unicode.L, unicode.M, unicode.N, unicode.P, unicode.S,
+		unicode.Z, unicode.Cc, unicode.Cf, unicode.Co, unicode.Cs)
+	alpha := in(unicode.L, unicode.Nl, unicode.Other_Alphabetic)
+	graph := func(r rune) bool {
+		return assigned(r) && !unicode.In(r, unicode.White_Space, unicode.Cc, unicode.Cs)
+	}
+	testCases := []struct {
+		name     string
+		expected func(rune) bool
+	}{
+		{"Assigned", assigned},
+		{"Alphabetic", alpha},
+		{"alpha", alpha},
+		{"Lowercase", in(unicode.Ll, unicode.Other_Lowercase)},
+		{"Upper", in(unicode.Lu, unicode.Other_Uppercase)},
+		{"alnum", func(r rune) bool { return alpha(r) || unicode.Is(unicode.Nd, r) }},
+		{"xdigit", in(unicode.Nd, unicode.Hex_Digit)},
+		{"blank", func(r rune) bool { return r == '\t' || unicode.Is(unicode.Zs, r) }},
+		{"graph", graph},
+		{"print", func(r rune) bool { return graph(r) || unicode.Is(unicode.Zs, r) }},
+		{"word", func(r rune) bool {
+			return alpha(r) || unicode.In(r, unicode.M, unicode.Nd, unicode.Pc, unicode.Join_Control)
+		}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			s, ok := Lookup(tc.name)
+			util.MustEqual(t, true, ok, "%q not found", tc.name)
+			testPredicateEquivalence(t, s, tc.expected)
+		})
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	t.Parallel()
+	for _, name := range []string{
+		"", "Nope", "gc=Greek", "sc=Lu", "White_Space=Yes", "nope=L", "=L",
+		"IsGreek",
+	} {
+		s, ok := Lookup(name)
+		util.Equal(t, false, ok, "%q: expected not found", name)
+		util.Equal(t, nil, s, "%q: expected nil set", name)
+	}
+}
+
+func TestLookupAliases(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		tables  map[string]*unicode.RangeTable
+		aliases map[string][]string
+	}{
+		{unicode.Categories, categoryAliases},
+		{unicode.Scripts, scriptAliases},
+		{unicode.Properties, propertyAliases},
+	}
+
+	for i, tc := range testCases {
+		// aliases must not clash. Those of tables added in later versions of
+		// Unicode than the one of the running toolchain are skipped
+		seen := map[string]string{}
+		for name := range tc.tables {
+			seen[looseName(name)] = name
+		}
+		for name, aliases := range tc.aliases {
+			if _, ok := tc.tables[name]; !ok {
+				continue
+			}
+			for _, alias := range aliases {
+				key := looseName(alias)
+				prev, ok := seen[key]
+				util.Equal(t, true, !ok || prev == name,
+					"index=%v; alias %q of %q clashes with %q", i, alias, name, prev)
+				seen[key] = name
+			}
+		}
+	}
+	for name := range unicode.Scripts {
+		_, ok := scriptAliases[name]
+		util.Equal(t, true, ok, "missing code of script %q", name)
+	}
+}
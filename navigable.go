@@ -0,0 +1,462 @@
+package runes
+
+import (
+	"cmp"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+func (x Union[T]) Next(r rune) (rune, bool) {
+	if !x.sorted() {
+		var res rune
+		var found bool
+		for i := range x {
+			if v, ok := setNext(x[i], r); ok && (!found || v < res) {
+				res, found = v, true
+			}
+		}
+		return res, found
+	}
+	for i := range x {
+		if r < 0 || uint32(r) <= x[i].Max() {
+			if v, ok := setNext(x[i], r); ok {
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (x Union[T]) Prev(r rune) (rune, bool) {
+	if r < 0 {
+		return 0, false
+	}
+	if !x.sorted() {
+		var res rune
+		var found bool
+		for i := range x {
+			if v, ok := setPrev(x[i], r); ok && (!found || v > res) {
+				res, found = v, true
+			}
+		}
+		return res, found
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		if uint32(r) >= x[i].Min() {
+			if v, ok := setPrev(x[i], r); ok {
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (x SortedUnion[T]) Next(r rune) (rune, bool) {
+	if len(x.elems) == 0 || (r >= 0 && uint32(r) > x.Max()) {
+		return 0, false
+	}
+	i := 0
+	if r >= 0 {
+		i = x.search(uint32(r))
+	}
+	// the bounds of a member need not be runes of it, so the following members
+	// are asked too
+	for ; i < len(x.elems); i++ {
+		if v, ok := setNext(x.elems[i], r); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+func (x SortedUnion[T]) Prev(r rune) (rune, bool) {
+	if len(x.elems) == 0 || r < 0 || uint32(r) < x.Min() {
+		return 0, false
+	}
+	i := x.search(min(uint32(r), x.Max()))
+	if uint32(r) < x.bounds[2*i] {
+		i--
+	}
+	for ; i >= 0; i-- {
+		if v, ok := setPrev(x.elems[i], r); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+func (x LinearSlice[T]) Next(r rune) (rune, bool) {
+	for i := range x {
+		if rune(x[i]) >= r {
+			return rune(x[i]), true
+		}
+	}
+	return 0, false
+}
+
+func (x LinearSlice[T]) Prev(r rune) (rune, bool) {
+	for i := len(x) - 1; i >= 0; i-- {
+		if rune(x[i]) <= r {
+			return rune(x[i]), true
+		}
+	}
+	return 0, false
+}
+
+func (x BinarySlice[T]) Next(r rune) (rune, bool) {
+	i, _ := x.search(r)
+	return sliceSelect(x, i)
+}
+
+func (x BinarySlice[T]) Prev(r rune) (rune, bool) {
+	i, found := x.search(r)
+	if !found {
+		i--
+	}
+	return sliceSelect(x, i)
+}
+
+// search returns the position where `r` is or would be in the slice, and
+// whether it was found.
+func (x BinarySlice[T]) search(r rune) (int, bool) {
+	return slices.BinarySearchFunc(x, r, func(v T, r rune) int {
+		return cmp.Compare(rune(v), r)
+	})
+}
+
+func (x RangeSlice[T]) Next(r rune) (rune, bool) {
+	i := x.search(r)
+	if i == len(x) {
+		return 0, false
+	}
+	return max(r, rune(x[i].From)), true
+}
+
+func (x RangeSlice[T]) Prev(r rune) (rune, bool) {
+	i := x.search(r)
+	if i < len(x) && rune(x[i].From) <= r {
+		return r, true
+	}
+	if i == 0 {
+		return 0, false
+	}
+	return rune(x[i-1].To), true
+}
+
+func (x StridedRanges[T]) Next(r rune) (rune, bool) {
+	for i := x.search(r); i < len(x); i++ {
+		if v, ok := x[i].Next(r); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+func (x StridedRanges[T]) Prev(r rune) (rune, bool) {
+	for i := min(x.search(r), len(x)-1); i >= 0; i-- {
+		if v, ok := x[i].Prev(r); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+func (x Interval[T]) Next(r rune) (rune, bool) {
+	if x.From > x.To || r > rune(x.To) {
+		return 0, false
+	}
+	return max(r, rune(x.From)), true
+}
+
+func (x Interval[T]) Prev(r rune) (rune, bool) {
+	if x.From > x.To || r < rune(x.From) {
+		return 0, false
+	}
+	return min(r, rune(x.To)), true
+}
+
+func (x Uniform[T]) Next(r rune) (rune, bool) {
+	return x.Select(x.Rank(r))
+}
+
+func (x Uniform[T]) Prev(r rune) (rune, bool) {
+	if r == math.MaxInt32 {
+		return x.Select(x.Len() - 1)
+	}
+	return x.Select(x.Rank(r+1) - 1)
+}
+
+func (x Bitmap) Next(r rune) (rune, bool) {
+	if len(x) < bmHdrLen || (r >= 0 && uint32(r) > x.Max()) {
+		return 0, false
+	}
+	lo := rune(x.Min())
+	u := max(r, lo) - lo
+	for i, mask := bmHdrLen+int(u>>3), byte(0xff)<<(u&7); i < len(x); i, mask = i+1, 0xff {
+		if b := x[i] & mask; b != 0 {
+			return lo + rune(i-bmHdrLen)<<3 + rune(bits.TrailingZeros8(b)), true
+		}
+	}
+	return 0, false
+}
+
+func (x Bitmap) Prev(r rune) (rune, bool) {
+	if len(x) < bmHdrLen || r < 0 || uint32(r) < x.Min() {
+		return 0, false
+	}
+	lo := rune(x.Min())
+	u := min(r, rune(x.Max())) - lo
+	for i, mask := bmHdrLen+int(u>>3), byte(0xff)>>(7-u&7); i >= bmHdrLen; i, mask = i-1, 0xff {
+		if b := x[i] & mask; b != 0 {
+			return lo + rune(i-bmHdrLen)<<3 + rune(7-bits.LeadingZeros8(b)), true
+		}
+	}
+	return 0, false
+}
+
+func (x TwoLevel) Next(r rune) (rune, bool) {
+	if len(x.Index) == 0 || (r >= 0 && uint32(r) > x.Max()) {
+		return 0, false
+	}
+	u := uint32(max(r, rune(x.Min())))
+	for i, mask := int(u>>blockBits-x.First), ^uint64(0)<<(u&blockMask); i < len(x.Index); i, mask = i+1, ^uint64(0) {
+		if b := x.leaf(i) & mask; b != 0 {
+			return rune(x.First+uint32(i))<<blockBits + rune(bits.TrailingZeros64(b)), true
+		}
+	}
+	return 0, false
+}
+
+func (x TwoLevel) Prev(r rune) (rune, bool) {
+	if len(x.Index) == 0 || r < 0 || uint32(r) < x.Min() {
+		return 0, false
+	}
+	u := uint32(min(r, rune(x.Max())))
+	for i, mask := int(u>>blockBits-x.First), ^uint64(0)>>(63-u&blockMask); i >= 0; i, mask = i-1, ^uint64(0) {
+		if b := x.leaf(i) & mask; b != 0 {
+			return rune(x.First+uint32(i))<<blockBits + rune(63-bits.LeadingZeros64(b)), true
+		}
+	}
+	return 0, false
+}
+
+func (x SparseBitmap) Next(r rune) (rune, bool) {
+	if len(x.Blocks) == 0 || (r >= 0 && uint32(r) > x.Max()) {
+		return 0, false
+	}
+	u := uint32(max(r, rune(x.Min())))
+	i := x.search(u >> blockBits)
+	mask := ^uint64(0)
+	if uint32(x.Blocks[i]) == u>>blockBits {
+		mask <<= u & blockMask
+	}
+	for ; i < len(x.Blocks); i, mask = i+1, ^uint64(0) {
+		if b := x.word(i) & mask; b != 0 {
+			return rune(x.Blocks[i])<<blockBits + rune(bits.TrailingZeros64(b)), true
+		}
+	}
+	return 0, false
+}
+
+func (x SparseBitmap) Prev(r rune) (rune, bool) {
+	if len(x.Blocks) == 0 || r < 0 || uint32(r) < x.Min() {
+		return 0, false
+	}
+	u := uint32(min(r, rune(x.Max())))
+	i := x.search(u >> blockBits)
+	mask := ^uint64(0)
+	if uint32(x.Blocks[i]) == u>>blockBits {
+		mask >>= 63 - u&blockMask
+	} else {
+		i-- // the block of u has no runes
+	}
+	for ; i >= 0; i, mask = i-1, ^uint64(0) {
+		if b := x.word(i) & mask; b != 0 {
+			return rune(x.Blocks[i])<<blockBits + rune(63-bits.LeadingZeros64(b)), true
+		}
+	}
+	return 0, false
+}
+
+func (x Roaring) Next(r rune) (rune, bool) {
+	if len(x.Keys) == 0 || (r >= 0 && uint32(r) > x.Max()) {
+		return 0, false
+	}
+	u := uint32(max(r, rune(x.Min())))
+	i := x.search(u >> roaringBits)
+	if uint32(x.Keys[i]) == u>>roaringBits {
+		if v, ok := x.container(i).next(uint16(u & roaringMask)); ok {
+			return rune(x.Keys[i])<<roaringBits + rune(v), true
+		}
+		i++
+	}
+	if i < len(x.Keys) {
+		return rune(x.Keys[i])<<roaringBits + rune(x.container(i).min()), true
+	}
+	return 0, false
+}
+
+func (x Roaring) Prev(r rune) (rune, bool) {
+	if len(x.Keys) == 0 || r < 0 || uint32(r) < x.Min() {
+		return 0, false
+	}
+	u := uint32(min(r, rune(x.Max())))
+	i := x.search(u >> roaringBits)
+	if uint32(x.Keys[i]) == u>>roaringBits {
+		if v, ok := x.container(i).prev(uint16(u & roaringMask)); ok {
+			return rune(x.Keys[i])<<roaringBits + rune(v), true
+		}
+	}
+	if i--; i >= 0 {
+		return rune(x.Keys[i])<<roaringBits + rune(x.container(i).max()), true
+	}
+	return 0, false
+}
+
+// next returns the smallest offset of the container that is greater than or
+// equal to `v`.
+func (c RoaringContainer) next(v uint16) (uint16, bool) {
+	switch c.Kind {
+	case RoaringBitmap:
+		for i, mask := int(v>>4), uint16(0xffff)<<(v&15); i < len(c.Data); i, mask = i+1, 0xffff {
+			if w := c.Data[i] & mask; w != 0 {
+				return uint16(i)<<4 + uint16(bits.TrailingZeros16(w)), true
+			}
+		}
+	case RoaringRuns:
+		if i := c.searchRun(v); i < len(c.Data) {
+			return max(v, c.Data[i]), true
+		}
+	default:
+		if i, _ := slices.BinarySearch(c.Data, v); i < len(c.Data) {
+			return c.Data[i], true
+		}
+	}
+	return 0, false
+}
+
+// prev returns the biggest offset of the container that is less than or equal
+// to `v`.
+func (c RoaringContainer) prev(v uint16) (uint16, bool) {
+	switch c.Kind {
+	case RoaringBitmap:
+		for i, mask := min(int(v>>4), len(c.Data)-1), uint16(0xffff)>>(15-v&15); i >= 0; i, mask = i-1, 0xffff {
+			if w := c.Data[i] & mask; w != 0 {
+				return uint16(i)<<4 + uint16(15-bits.LeadingZeros16(w)), true
+			}
+		}
+	case RoaringRuns:
+		i := c.searchRun(v)
+		if i < len(c.Data) && c.Data[i] <= v {
+			return v, true
+		}
+		if i >= 2 {
+			return c.Data[i-1], true
+		}
+	default:
+		i, found := slices.BinarySearch(c.Data, v)
+		if found {
+			return v, true
+		}
+		if i > 0 {
+			return c.Data[i-1], true
+		}
+	}
+	return 0, false
+}
+
+func (x MixedUnion) Next(r rune) (rune, bool) {
+	if x.segments() == 0 || (r >= 0 && uint32(r) > x.Max()) {
+		return 0, false
+	}
+	if r < 0 || uint32(r) < x.Min() {
+		return rune(x.Min()), true
+	}
+	i := searchBounds(x.Bounds, uint32(r))
+	if v, ok := setNext(x.segment(i), r); ok {
+		return v, true
+	}
+	if i++; i < x.segments() {
+		return rune(x.Bounds[2*i]), true
+	}
+	return 0, false
+}
+
+func (x MixedUnion) Prev(r rune) (rune, bool) {
+	if x.segments() == 0 || r < 0 || uint32(r) < x.Min() {
+		return 0, false
+	}
+	i := min(searchBounds(x.Bounds, min(uint32(r), x.Max())), x.segments()-1)
+	if uint32(r) >= x.Bounds[2*i] {
+		if v, ok := setPrev(x.segment(i), r); ok {
+			return v, true
+		}
+	}
+	if i--; i >= 0 {
+		return rune(x.Bounds[2*i+1]), true
+	}
+	return 0, false
+}
+
+func (x wordBitmap) Next(r rune) (rune, bool) {
+	if r < 0 || uint32(r) < x.lo {
+		r = rune(x.lo)
+	}
+	off := uint32(r) - x.lo
+	mask := ^uint64(0) << (off & blockMask)
+	for i := int(off >> blockBits); i < len(x.words); i, mask = i+1, ^uint64(0) {
+		if w := x.words[i] & mask; w != 0 {
+			return rune(x.lo) + rune(i)<<blockBits + rune(bits.TrailingZeros64(w)), true
+		}
+	}
+	return 0, false
+}
+
+func (x wordBitmap) Prev(r rune) (rune, bool) {
+	if r < 0 || uint32(r) < x.lo || len(x.words) == 0 {
+		return 0, false
+	}
+	off := uint32(r) - x.lo
+	i, mask := int(off>>blockBits), ^uint64(0)>>(63-off&blockMask)
+	if i >= len(x.words) {
+		i, mask = len(x.words)-1, ^uint64(0)
+	}
+	for ; i >= 0; i, mask = i-1, ^uint64(0) {
+		if w := x.words[i] & mask; w != 0 {
+			return rune(x.lo) + rune(i)<<blockBits + rune(63-bits.LeadingZeros64(w)), true
+		}
+	}
+	return 0, false
+}
+
+// setNext returns the smallest rune of `s` that is greater than or equal to
+// `r`, using [Navigable] if implemented.
+func setNext(s MinMaxSet, r rune) (rune, bool) {
+	if x, ok := s.(Navigable); ok {
+		return x.Next(r)
+	}
+	for lo, hi := range setRanges(s) {
+		if r <= hi {
+			return max(r, lo), true
+		}
+	}
+	return 0, false
+}
+
+// setPrev returns the biggest rune of `s` that is less than or equal to `r`,
+// using [Navigable] if implemented.
+func setPrev(s MinMaxSet, r rune) (rune, bool) {
+	if x, ok := s.(Navigable); ok {
+		return x.Prev(r)
+	}
+	var res rune
+	var found bool
+	for lo, hi := range setRanges(s) {
+		if r < lo {
+			break
+		}
+		res, found = min(r, hi), true
+	}
+	return res, found
+}
@@ -0,0 +1,168 @@
+package runes
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+	"unicode"
+
+	"github.com/diegommm/runes/util"
+)
+
+var _ = []Navigable{
+	Union[MinMaxSet]{},
+	SortedUnion[MinMaxSet]{},
+	LinearSlice[uint8]{},
+	BinarySlice[uint16]{},
+	RangeSlice[uint8]{},
+	StridedRanges[uint8]{},
+	Interval[uint32]{},
+	Uniform[rune]{},
+	Bitmap(""),
+	TwoLevel{},
+	SparseBitmap{},
+	Roaring{},
+	MixedUnion{},
+}
+
+func TestNavigable(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		set   Navigable
+		runes []rune
+	}{
+		{LinearSlice[uint8](nil), nil},
+		{LinearSlice[uint8]{1, 2, 3, 5}, []rune{1, 2, 3, 5}},
+		{BinarySlice[uint8](nil), nil},
+		{BinarySlice[rune]{1, 0x10000, 0x10001}, []rune{1, 0x10000, 0x10001}},
+		{RangeSlice[uint8](nil), nil},
+		{RangeSlice[uint8]{{1, 2}, {3, 3}, {5, 6}, {0xff, 0xff}}, []rune{1, 2, 3, 5, 6, 0xff}},
+		{RangeSlice[rune]{{0, 0}, {0x10000, 0x10002}}, []rune{0, 0x10000, 0x10001, 0x10002}},
+		{StridedRanges[uint8](nil), nil},
+		{StridedRanges[uint8]{{1, 3, 1}, {5, 9, 2}, {0xff, 0xff, 1}}, []rune{1, 2, 3, 5, 7, 9, 0xff}},
+		{StridedRanges[rune]{{0, 0, 1}, {0x10000, 0x10004, 2}}, []rune{0, 0x10000, 0x10002, 0x10004}},
+		{Interval[uint8]{'a', 'c'}, []rune{'a', 'b', 'c'}},
+		{Interval[uint8]{'c', 'a'}, nil},
+		{Uniform[uint8]{}, nil},
+		{Uniform[uint8]{253, 255, 1}, []rune{253, 254, 255}},
+		{Uniform[uint8]{3, 31, 7}, []rune{3, 10, 17, 24, 31}},
+		{NewBitmap(nil), nil},
+		{NewBitmap([]rune{1, 7, 8, 9, 17}), []rune{1, 7, 8, 9, 17}},
+		{NewBitmap(slices.Collect(util.Seq(3, 300, 3))), slices.Collect(util.Seq(3, 300, 3))},
+		{NewTwoLevel(nil), nil},
+		{NewTwoLevel([]rune{1, 63, 64, 200, 0x10000}), []rune{1, 63, 64, 200, 0x10000}},
+		{NewTwoLevel(slices.Collect(util.Seq(3, 300, 3))), slices.Collect(util.Seq(3, 300, 3))},
+		{NewSparseBitmap(nil), nil},
+		{NewSparseBitmap([]rune{1, 63, 64, 200, 0x10000}), []rune{1, 63, 64, 200, 0x10000}},
+		{NewSparseBitmap(slices.Collect(util.Seq(3, 300, 3))), slices.Collect(util.Seq(3, 300, 3))},
+		{NewRoaring(nil), nil},
+		{NewRoaring(slices.Collect(util.Concat(runes(1, 3, 99), util.Seq(0x1000, 0x1fff, 1), util.Seq(0x2000, 0x2fff, 3), util.Seq(0x10000, 0x10100, 1)))), slices.Collect(util.Concat(runes(1, 3, 99), util.Seq(0x1000, 0x1fff, 1), util.Seq(0x2000, 0x2fff, 3), util.Seq(0x10000, 0x10100, 1)))},
+		{Union[MinMaxSet](nil), nil},
+		{
+			set:   Union[MinMaxSet]{Interval[uint8]{1, 3}, Interval[uint8]{4, 5}, LinearSlice[uint8]{7, 9}},
+			runes: []rune{1, 2, 3, 4, 5, 7, 9},
+		},
+		{
+			set:   Union[MinMaxSet]{Interval[uint8]{1, 3}, LinearSlice[uint8]{2, 9}, Interval[uint8]{5, 8}},
+			runes: []rune{1, 2, 3, 5, 6, 7, 8, 9},
+		},
+		{
+			set: Union[MinMaxSet]{minMaxFunc{
+				ContainsFunc: func(r rune) bool { return r%2 == 0 },
+				min:          2,
+				max:          6,
+			}},
+			runes: []rune{2, 4, 6},
+		},
+		{MixedUnion{}, nil},
+		{mixedUnion, mixedUnionRunes},
+		{NewMixedUnion(slices.Collect(util.RangeTableIter(util.Tables["L"]))), slices.Collect(util.RangeTableIter(util.Tables["L"]))},
+		{MustSortedUnion[MinMaxSet](), nil},
+		{
+			set:   MustSortedUnion[MinMaxSet](Interval[uint8]{1, 3}, LinearSlice[uint16]{7, 0x100}, NewBitmap([]rune{0x200, 0x10000})),
+			runes: []rune{1, 2, 3, 7, 0x100, 0x200, 0x10000},
+		},
+		{
+			// the Min and Max of these members are not runes of them
+			set: MustSortedUnion[MinMaxSet](
+				Uniform[uint8]{10, 13, 2},
+				And(LinearSlice[uint8]{20, 50, 90}, LinearSlice[uint8]{30, 50, 100}),
+				Interval[uint8]{200, 205},
+			),
+			runes: []rune{10, 12, 50, 200, 201, 202, 203, 204, 205},
+		},
+		{
+			set: MustSortedUnion[MinMaxSet](
+				Interval[uint8]{1, 2},
+				And(LinearSlice[uint8]{10, 20}, LinearSlice[uint8]{15}),
+				Interval[uint8]{30, 31},
+			),
+			runes: []rune{1, 2, 30, 31},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			var hi rune = 1
+			if len(tc.runes) > 0 {
+				hi = tc.runes[len(tc.runes)-1] + 2
+			}
+			probes := append(slices.Collect(util.Seq(-1, hi, 1)), math.MinInt32, math.MaxInt32)
+			testNavigable(t, tc.set, tc.runes, probes)
+		})
+	}
+}
+
+func TestNavigableCompile(t *testing.T) {
+	t.Parallel()
+	tables := []*unicode.RangeTable{
+		unicode.White_Space,
+		unicode.Greek,
+		unicode.Upper,
+		unicode.Letter,
+	}
+
+	for i, rt := range tables {
+		rs := slices.Collect(util.RangeTableIter(rt))
+		var probes []rune
+		for j := 0; j < len(rs); j += 97 {
+			probes = append(probes, rs[j]-1, rs[j], rs[j]+1)
+		}
+		for _, stepCost := range []int{0, defaultStepCost, 1000} {
+			t.Run(fmt.Sprintf("index=%v,StepCost=%v", i, stepCost), func(t *testing.T) {
+				t.Parallel()
+				testNavigable(t, Compile(rs, StepCost(stepCost)).(Navigable), rs, probes)
+			})
+		}
+	}
+}
+
+// testNavigable checks the methods of `s`, which has exactly the runes `rs`,
+// for each of the `probes`.
+func testNavigable(t *testing.T, s Navigable, rs, probes []rune) {
+	t.Helper()
+	for _, r := range probes {
+		i, found := slices.BinarySearch(rs, r)
+
+		var expectedNext rune
+		if i < len(rs) {
+			expectedNext = rs[i]
+		}
+		got, ok := s.Next(r)
+		util.MustEqual(t, i < len(rs), ok, "Next(0x%x) ok", r)
+		util.MustEqual(t, expectedNext, got, "Next(0x%x)", r)
+
+		if !found {
+			i--
+		}
+		var expectedPrev rune
+		if i >= 0 {
+			expectedPrev = rs[i]
+		}
+		got, ok = s.Prev(r)
+		util.MustEqual(t, i >= 0, ok, "Prev(0x%x) ok", r)
+		util.MustEqual(t, expectedPrev, got, "Prev(0x%x)", r)
+	}
+}
@@ -0,0 +1,358 @@
+package runes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PatternError is returned by [ParsePattern] for invalid patterns.
+type PatternError struct {
+	Pattern string
+	Offset  int // offset in bytes of the error in Pattern
+	Msg     string
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("runes: invalid pattern %q at offset %d: %s", e.Pattern, e.Offset, e.Msg)
+}
+
+// ParsePattern returns the set described by a pattern in the syntax of
+// Unicode Technical Standard #18 and ICU's UnicodeSet, like `[\p{Greek}a-z]`
+// or `[[:L:]--[a-z]]`. The result is optimized with [Compile].
+//
+// A pattern is a property or a bracket expression. Properties are written as
+// `\p{Name}`, `\p{Property=Value}`, `[:Name:]` or `\pL` for single letter
+// names, and are resolved with [Lookup]. They are negated with `\P{Name}`,
+// `\p{^Name}` or `[:^Name:]`.
+//
+// A bracket expression is enclosed in `[` and `]`, and holds a sequence of
+// runes, ranges like `a-z`, properties and nested bracket expressions, whose
+// union is taken. A leading `^` complements the expression. Between two
+// items, `&&` takes the intersection and `--` takes the difference of
+// everything to its left and the item to its right, and so do `&` and `-` when
+// both sides are properties or bracket expressions.
+//
+// Runes can be escaped with `\uXXXX`, `\u{X...}`, `\UXXXXXXXX`, `\xXX`,
+// `\x{X...}`, the usual `\t`, `\n`, `\r`, `\f`, `\v`, `\a` and `\e`, or a
+// backslash before any rune that is not a letter or digit. Whitespace is
+// ignored unless escaped.
+func ParsePattern(s string) (MinMaxSet, error) {
+	p := &patternParser{s: s}
+	p.skipSpace()
+	b, ok := p.setOperand()
+	if !ok {
+		return nil, p.errorf(p.pos, "expected '[' or a property")
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.skipSpace()
+	if p.pos < len(s) {
+		return nil, p.errorf(p.pos, "unexpected trailing characters")
+	}
+	return compileBounds(b), nil
+}
+
+// patternParser parses patterns into the bounds of their sets, as used by
+// combineBounds, so that sets are compiled once at the end.
+type patternParser struct {
+	s   string
+	pos int
+	err error // the first error found
+}
+
+func (p *patternParser) errorf(offset int, format string, args ...any) error {
+	if p.err == nil {
+		p.err = &PatternError{Pattern: p.s, Offset: offset, Msg: fmt.Sprintf(format, args...)}
+	}
+	return p.err
+}
+
+// anyBounds are the bounds of the set of all the runes.
+var anyBounds = []rune{0, utf8.MaxRune + 1}
+
+func (p *patternParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\n\r\f\v", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *patternParser) peek(prefix string) bool {
+	return strings.HasPrefix(p.s[p.pos:], prefix)
+}
+
+// setAt reports whether a bracket expression or a property starts at the
+// given offset, after skipping whitespace.
+func (p *patternParser) setAt(offset int) bool {
+	rest := strings.TrimLeft(p.s[offset:], " \t\n\r\f\v")
+	return strings.HasPrefix(rest, "[") || strings.HasPrefix(rest, `\p`) ||
+		strings.HasPrefix(rest, `\P`)
+}
+
+// setOperand parses a bracket expression or a property at the current
+// position, if there is one.
+func (p *patternParser) setOperand() (b []rune, ok bool) {
+	switch {
+	case p.peek("[:"), p.peek(`\p`), p.peek(`\P`):
+		return p.property(), true
+	case p.peek("["):
+		return p.bracket(), true
+	}
+	return nil, false
+}
+
+// bracket parses a bracket expression.
+func (p *patternParser) bracket() []rune {
+	start := p.pos
+	p.pos++ // '['
+	p.skipSpace()
+	negated := p.peek("^")
+	if negated {
+		p.pos++
+	}
+
+	var b []rune
+	hasItems, lastWasSet := false, false
+	for {
+		p.skipSpace()
+		if p.pos == len(p.s) {
+			p.errorf(start, "missing closing ']'")
+			return nil
+		}
+		if p.peek("]") {
+			break
+		}
+
+		op, opPos := or, p.pos
+		switch {
+		case p.peek("&&"), p.peek("--"):
+			if !hasItems {
+				p.errorf(opPos, "missing left operand of %q", p.s[opPos:opPos+2])
+				return nil
+			}
+			p.pos += 2
+		case lastWasSet && (p.peek("&") || p.peek("-")) && p.setAt(p.pos+1):
+			p.pos++
+		}
+		if p.pos != opPos {
+			op = andNot
+			if p.s[opPos] == '&' {
+				op = and
+			}
+			p.skipSpace()
+			if p.pos == len(p.s) || p.peek("]") {
+				p.errorf(opPos, "missing right operand of %q", p.s[opPos:p.pos])
+				return nil
+			}
+		}
+
+		item, isSet := p.item()
+		if p.err != nil {
+			return nil
+		}
+		b = combineBounds(b, item, op)
+		hasItems, lastWasSet = true, isSet
+	}
+	p.pos++ // ']'
+	if negated {
+		b = combineBounds(anyBounds, b, andNot)
+	}
+	return b
+}
+
+// item parses a set operand, a rune or a range of runes. A '-' is taken as a
+// literal when it is the first or last item.
+func (p *patternParser) item() (b []rune, isSet bool) {
+	if b, ok := p.setOperand(); ok {
+		return b, true
+	}
+	lo := p.char()
+	if p.err != nil {
+		return nil, false
+	}
+	save := p.pos
+	p.skipSpace()
+	if !p.peek("-") || p.peek("--") {
+		p.pos = save
+		return []rune{lo, lo + 1}, false
+	}
+	dash := p.pos
+	p.pos++
+	p.skipSpace()
+	if p.pos == len(p.s) || p.peek("]") {
+		// a trailing '-' is a literal, and the bracket reports a missing ']'
+		p.pos = dash
+		return []rune{lo, lo + 1}, false
+	}
+	if p.setAt(p.pos) {
+		p.errorf(dash, "invalid range end")
+		return nil, false
+	}
+	hiPos := p.pos
+	hi := p.char()
+	if p.err != nil {
+		return nil, false
+	}
+	if lo > hi {
+		p.errorf(hiPos, "invalid range %q", p.s[save:p.pos])
+		return nil, false
+	}
+	return []rune{lo, hi + 1}, false
+}
+
+// char parses a literal or escaped rune.
+func (p *patternParser) char() rune {
+	start := p.pos
+	r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+	if r == utf8.RuneError && size <= 1 {
+		p.errorf(start, "invalid UTF-8")
+		return 0
+	}
+	p.pos += size
+	switch r {
+	case '[', ']':
+		p.errorf(start, "unexpected %q", r)
+		return 0
+	case '\\':
+	default:
+		return r
+	}
+
+	if p.pos == len(p.s) {
+		p.errorf(start, "trailing backslash")
+		return 0
+	}
+	r, size = utf8.DecodeRuneInString(p.s[p.pos:])
+	p.pos += size
+	switch r {
+	case 'u':
+		if p.peek("{") {
+			return p.bracedHex(start)
+		}
+		return p.hex(start, 4)
+	case 'U':
+		return p.hex(start, 8)
+	case 'x':
+		if p.peek("{") {
+			return p.bracedHex(start)
+		}
+		return p.hex(start, 2)
+	case 't':
+		return '\t'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 'f':
+		return '\f'
+	case 'v':
+		return '\v'
+	case 'a':
+		return '\a'
+	case 'e':
+		return 0x1b
+	}
+	if r < utf8.RuneSelf && (r >= '0' && r <= '9' || r|0x20 >= 'a' && r|0x20 <= 'z') {
+		p.errorf(start, "unknown escape %q", p.s[start:p.pos])
+		return 0
+	}
+	return r
+}
+
+// hex parses a rune with exactly `n` hex digits.
+func (p *patternParser) hex(start, n int) rune {
+	if len(p.s)-p.pos < n {
+		p.errorf(start, "expected %d hex digits", n)
+		return 0
+	}
+	return p.parseHex(start, p.s[p.pos:p.pos+n], n)
+}
+
+// bracedHex parses a rune with one to six hex digits in braces.
+func (p *patternParser) bracedHex(start int) rune {
+	end := strings.IndexByte(p.s[p.pos:], '}')
+	if end < 0 {
+		p.errorf(start, "missing closing '}'")
+		return 0
+	}
+	digits := p.s[p.pos+1 : p.pos+end]
+	if len(digits) == 0 || len(digits) > 6 {
+		p.errorf(start, "expected one to six hex digits")
+		return 0
+	}
+	p.pos++ // '{'
+	r := p.parseHex(start, digits, len(digits))
+	p.pos++ // '}'
+	return r
+}
+
+func (p *patternParser) parseHex(start int, digits string, n int) rune {
+	v, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		p.errorf(start, "invalid hex digits %q", digits)
+		return 0
+	}
+	if v > utf8.MaxRune {
+		p.errorf(start, "rune out of range %q", p.s[start:p.pos+n])
+		return 0
+	}
+	p.pos += n
+	return rune(v)
+}
+
+// property parses a property in one of the forms `\p{Name}`, `\P{Name}`,
+// `\pL`, `\PL` or `[:Name:]`.
+func (p *patternParser) property() []rune {
+	start := p.pos
+	var name string
+	var negated bool
+	if p.peek("[:") {
+		end := strings.Index(p.s[p.pos+2:], ":]")
+		if end < 0 {
+			p.errorf(start, "missing closing ':]'")
+			return nil
+		}
+		name = p.s[p.pos+2 : p.pos+2+end]
+		p.pos += end + 4
+	} else {
+		negated = p.s[p.pos+1] == 'P'
+		p.pos += 2
+		switch {
+		case p.pos == len(p.s):
+			p.errorf(start, "missing property name")
+			return nil
+		case p.peek("{"):
+			end := strings.IndexByte(p.s[p.pos:], '}')
+			if end < 0 {
+				p.errorf(start, "missing closing '}'")
+				return nil
+			}
+			name = p.s[p.pos+1 : p.pos+end]
+			p.pos += end + 1
+		default:
+			r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+			if r >= utf8.RuneSelf || !(r|0x20 >= 'a' && r|0x20 <= 'z') {
+				p.errorf(start, "invalid property name")
+				return nil
+			}
+			name = p.s[p.pos : p.pos+size]
+			p.pos += size
+		}
+	}
+	if strings.HasPrefix(name, "^") {
+		name, negated = name[1:], !negated
+	}
+
+	s, ok := Lookup(name)
+	if !ok {
+		p.errorf(start, "unknown property %q", name)
+		return nil
+	}
+	b := setBounds(s)
+	if negated {
+		b = combineBounds(anyBounds, b, andNot)
+	}
+	return b
+}
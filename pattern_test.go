@@ -0,0 +1,113 @@
+package runes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"unicode"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestParsePattern(t *testing.T) {
+	t.Parallel()
+	isLetter := func(r rune) bool { return unicode.Is(unicode.L, r) }
+	isGreek := func(r rune) bool { return unicode.Is(unicode.Greek, r) }
+	between := func(r, lo, hi rune) bool { return r >= lo && r <= hi }
+	testCases := []struct {
+		pattern  string
+		expected func(rune) bool
+	}{
+		{`[]`, func(r rune) bool { return false }},
+		{`[^]`, func(r rune) bool { return r >= 0 && r <= unicode.MaxRune }},
+		{`[abc]`, func(r rune) bool { return r == 'a' || r == 'b' || r == 'c' }},
+		{`[a-z]`, func(r rune) bool { return between(r, 'a', 'z') }},
+		{`[ a - z 0-9 ]`, func(r rune) bool { return between(r, 'a', 'z') || between(r, '0', '9') }},
+		{`[^a-z]`, func(r rune) bool { return r >= 0 && r <= unicode.MaxRune && !between(r, 'a', 'z') }},
+		{`[-a]`, func(r rune) bool { return r == '-' || r == 'a' }},
+		{`[a-]`, func(r rune) bool { return r == '-' || r == 'a' }},
+		{`[a&b]`, func(r rune) bool { return r == '&' || r == 'a' || r == 'b' }},
+		{`[αω]`, func(r rune) bool { return r == 'α' || r == 'ω' }},
+		{`[Ͱ-Ͽ]`, func(r rune) bool { return between(r, 0x370, 0x3ff) }},
+		{`[\x41\x{1F600}\u{10FFFF}\U0001F601]`, func(r rune) bool {
+			return r == 'A' || r == 0x1f600 || r == 0x1f601 || r == unicode.MaxRune
+		}},
+		{`[\t\n\r\f\v\a\e\ \]\[\\\-]`, func(r rune) bool {
+			switch r {
+			case '\t', '\n', '\r', '\f', '\v', '\a', 0x1b, ' ', ']', '[', '\\', '-':
+				return true
+			}
+			return false
+		}},
+		{`\p{Greek}`, isGreek},
+		{`\P{Greek}`, func(r rune) bool { return r >= 0 && r <= unicode.MaxRune && !isGreek(r) }},
+		{`\p{^Greek}`, func(r rune) bool { return r >= 0 && r <= unicode.MaxRune && !isGreek(r) }},
+		{`\pL`, isLetter},
+		{`[:L:]`, isLetter},
+		{`[:^L:]`, func(r rune) bool { return r >= 0 && r <= unicode.MaxRune && !isLetter(r) }},
+		{`[\p{Greek}Ͱ-Ͽ]`, func(r rune) bool { return isGreek(r) || between(r, 0x370, 0x3ff) }},
+		{`[[:L:]-[a-z]]`, func(r rune) bool { return isLetter(r) && !between(r, 'a', 'z') }},
+		{`[[:L:]--[a-z]]`, func(r rune) bool { return isLetter(r) && !between(r, 'a', 'z') }},
+		{`[\p{L}&&\p{Greek}]`, func(r rune) bool { return isLetter(r) && isGreek(r) }},
+		{`[\p{L}&\p{Greek}]`, func(r rune) bool { return isLetter(r) && isGreek(r) }},
+		{`[a-z--[aeiou]]`, func(r rune) bool { return between(r, 'b', 'z') && r != 'e' && r != 'i' && r != 'o' && r != 'u' }},
+		{`[a-z&&[^aeiou]]`, func(r rune) bool { return between(r, 'b', 'z') && r != 'e' && r != 'i' && r != 'o' && r != 'u' }},
+		{`[[a-c][x-z]--b]`, func(r rune) bool { return r == 'a' || r == 'c' || between(r, 'x', 'z') }},
+		{`[\p{gc=Lu}[:digit:]]`, func(r rune) bool { return unicode.IsUpper(r) || unicode.IsDigit(r) }},
+		{`[^[^a]]`, func(r rune) bool { return r == 'a' }},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			s, err := ParsePattern(tc.pattern)
+			util.MustEqual(t, nil, err, "pattern %q", tc.pattern)
+			testPredicateEquivalence(t, s, tc.expected)
+		})
+	}
+}
+
+func TestParsePatternErrors(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		pattern string
+		offset  int
+	}{
+		{``, 0},
+		{`a`, 0},
+		{`[a`, 0},
+		{`[a-`, 0},
+		{`[a- `, 0},
+		{`[a]]`, 3},
+		{`  [a] b`, 6},
+		{`[z-a]`, 3},
+		{`[a-[b]]`, 2},
+		{`[&&a]`, 1},
+		{`[a--]`, 2},
+		{`[[a]&&]`, 4},
+		{`[\q]`, 1},
+		{`[\u12]`, 1},
+		{`[\u12G4]`, 1},
+		{`[\x{}]`, 1},
+		{`[\x{110000}]`, 1},
+		{`[\x{41]`, 1},
+		{`[\U00110000]`, 1},
+		{`[a\`, 2},
+		{"[\xff]", 1},
+		{`\p{Nope}`, 0},
+		{`[ab\p{Nope}]`, 3},
+		{`\p{L`, 0},
+		{`\p`, 0},
+		{`\p1`, 0},
+		{`[:L]`, 0},
+		{`[:]`, 0},
+	}
+
+	for i, tc := range testCases {
+		_, err := ParsePattern(tc.pattern)
+		var pe *PatternError
+		util.MustEqual(t, true, errors.As(err, &pe), "index=%v; unexpected error: %v", i, err)
+		util.Equal(t, tc.offset, pe.Offset, "index=%v; unexpected offset: %v", i, err)
+		util.Equal(t, tc.pattern, pe.Pattern, "index=%v; unexpected pattern", i)
+	}
+}
@@ -0,0 +1,112 @@
+package runes
+
+import (
+	"unicode"
+)
+
+// FromRangeTable returns a [MinMaxSet] with the runes of the given table, which
+// must be valid as documented in package unicode. Each Range16 and Range32 is
+// mapped onto an [Interval] or a [Uniform], and consecutive ranges are merged
+// into a [Bitmap] when that is cheaper, joining the pieces in a [SortedUnion].
+// The cost model and options are the same as for [Compile]. The first
+// LatinOffset ranges of the table are never merged with the others, so Latin-1
+// runes are looked up in pieces of their own.
+func FromRangeTable(rt *unicode.RangeTable, opts ...Option) MinMaxSet {
+	latinOffset := min(max(rt.LatinOffset, 0), len(rt.R16))
+	latinAtoms := range16Atoms(nil, rt.R16[:latinOffset])
+	atoms := range16Atoms(nil, rt.R16[latinOffset:])
+	for _, r := range rt.R32 {
+		atoms = appendRangeAtom(atoms, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+
+	c := newCompileConfig(opts)
+	return c.joinSegments(append(c.segment(latinAtoms), c.segment(atoms)...))
+}
+
+func range16Atoms(atoms []atom, r16 []unicode.Range16) []atom {
+	for _, r := range r16 {
+		atoms = appendRangeAtom(atoms, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	return atoms
+}
+
+// appendRangeAtom appends the given range to `atoms` as a single atom.
+func appendRangeAtom(atoms []atom, lo, hi, stride rune) []atom {
+	if lo > hi {
+		return atoms
+	}
+	stride = max(stride, 1)
+	a := atom{lo: lo, stride: 1, n: int((hi-lo)/stride) + 1}
+	a.hi = lo + rune(a.n-1)*stride
+	if a.n > 1 {
+		a.stride = stride
+	}
+	return append(atoms, a)
+}
+
+// ToRangeTable returns the *unicode.RangeTable with the runes of the given set,
+// encoded with the fewest ranges possible. Ranges are split at 0x10000 between
+// R16 and R32, and LatinOffset is set to the number of R16 ranges within
+// Latin-1.
+func ToRangeTable(s MinMaxSet) *unicode.RangeTable {
+	var runs16, runs32 [][2]rune
+	for lo, hi := range setRanges(s) {
+		if hi < 0 {
+			continue
+		}
+		lo = max(lo, 0)
+		if lo <= maxUint16 {
+			runs16 = append(runs16, [2]rune{lo, min(hi, maxUint16)})
+		}
+		if hi > maxUint16 {
+			runs32 = append(runs32, [2]rune{max(lo, maxUint16+1), hi})
+		}
+	}
+
+	rt := new(unicode.RangeTable)
+	for _, r := range minimalRanges(runs16) {
+		rt.R16 = append(rt.R16, unicode.Range16{Lo: uint16(r.Lo), Hi: uint16(r.Hi), Stride: uint16(r.Stride)})
+		if r.Hi <= unicode.MaxLatin1 {
+			rt.LatinOffset++
+		}
+	}
+	rt.R32 = minimalRanges(runs32)
+	return rt
+}
+
+// minimalRanges returns the fewest ranges with the runes of the given runs of
+// consecutive runes, which must be sorted and not adjacent. Each range takes
+// the first rune not yet encoded and uses the distance to the next one as its
+// stride, extending as long as the following runes keep the same stride, like
+// the tables in package unicode. That is optimal, since encoding the runes
+// after a given one never takes more ranges than encoding those from it.
+func minimalRanges(runs [][2]rune) []unicode.Range32 {
+	var res []unicode.Range32
+	appendRange := func(lo, hi, stride rune) {
+		res = append(res, unicode.Range32{Lo: uint32(lo), Hi: uint32(hi), Stride: uint32(stride)})
+	}
+	// the first `skip` runes of run k are already encoded
+	for k, skip := 0, rune(0); k < len(runs); {
+		lo, hi := runs[k][0]+skip, runs[k][1]
+		switch {
+		case lo > hi:
+			k, skip = k+1, 0
+		case lo < hi:
+			appendRange(lo, hi, 1)
+			k, skip = k+1, 0
+		case k == len(runs)-1:
+			appendRange(lo, lo, 1)
+			k++
+		default:
+			// a stride over 1 takes the first rune of the next run, and
+			// extends over the following runs of a single rune
+			stride, j := runs[k+1][0]-lo, k+1
+			for j+1 < len(runs) && runs[j][0] == runs[j][1] && runs[j+1][0]-runs[j][1] == stride {
+				j++
+			}
+			appendRange(lo, runs[j][0], stride)
+			k, skip = j, 1
+		}
+	}
+	return res
+}
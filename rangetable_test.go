@@ -0,0 +1,146 @@
+package runes
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestFromRangeTable(t *testing.T) {
+	t.Parallel()
+	testCases := []*unicode.RangeTable{
+		unicode.White_Space,
+		unicode.Greek,
+		unicode.Upper,
+		unicode.Lower,
+		unicode.Letter,
+		unicode.Punct,
+		unicode.Cuneiform,
+		{},
+		{R16: []unicode.Range16{{'a', 'z', 1}}, LatinOffset: 1},
+	}
+
+	for i, rt := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			testRangeTableEquivalence(t, FromRangeTable(rt), rt)
+		})
+	}
+}
+
+func TestFromRangeTableLatinOffset(t *testing.T) {
+	t.Parallel()
+	rt := &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{'a', 'c', 1},
+			{0x100, 0x102, 1},
+		},
+		LatinOffset: 1,
+	}
+	// with a high step cost both ranges would be merged in a single Bitmap
+	got := FromRangeTable(rt, StepCost(1000))
+	util.Equal(t, fmt.Sprintf("%#v", MustSortedUnion(Interval[uint16]{'a', 'c'}, Interval[uint16]{0x100, 0x102})),
+		fmt.Sprintf("%#v", got), "unexpected set")
+}
+
+// testRangeTableEquivalence checks that `s` contains exactly the runes of
+// `rt`.
+func testRangeTableEquivalence(t *testing.T, s Set, rt *unicode.RangeTable) {
+	t.Helper()
+	for r := rune(-1); r <= utf8.MaxRune+1; r++ {
+		util.MustEqual(t, unicode.Is(rt, r), s.Contains(r), "rune=0x%x", r)
+	}
+}
+
+func TestToRangeTable(t *testing.T) {
+	t.Parallel()
+	testCases := []*unicode.RangeTable{
+		unicode.White_Space,
+		unicode.Greek,
+		unicode.Upper,
+		unicode.Lower,
+		unicode.Letter,
+		unicode.Punct,
+		unicode.Cuneiform,
+		unicode.Noncharacter_Code_Point,
+	}
+
+	for i, rt := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			got := ToRangeTable(FromRangeTable(rt))
+			util.Equal(t, true, equalRangeTables(rt, got), "round trip; got: %#v", got)
+		})
+	}
+}
+
+func TestToRangeTableMinimal(t *testing.T) {
+	t.Parallel()
+	for _, tables := range []map[string]*unicode.RangeTable{unicode.Categories, unicode.Scripts, unicode.Properties} {
+		for name, rt := range tables {
+			got := ToRangeTable(Compile(slices.Collect(util.RangeTableIter(rt))))
+			util.Equal(t, len(rt.R16), len(got.R16), "%s: R16 ranges", name)
+			util.Equal(t, len(rt.R32), len(got.R32), "%s: R32 ranges", name)
+		}
+	}
+}
+
+func TestToRangeTableEncoding(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		rs       []rune
+		expected *unicode.RangeTable
+	}{
+		{nil, &unicode.RangeTable{}},
+		{
+			rs: []rune{'a'},
+			expected: &unicode.RangeTable{
+				R16:         []unicode.Range16{{'a', 'a', 1}},
+				LatinOffset: 1,
+			},
+		},
+		{
+			rs: []rune{1, 3, 4, 5, 0x100, 0x110},
+			expected: &unicode.RangeTable{
+				R16:         []unicode.Range16{{1, 3, 2}, {4, 5, 1}, {0x100, 0x110, 0x10}},
+				LatinOffset: 2,
+			},
+		},
+		{
+			rs: []rune{0xfffc, 0xfffe, 0x10000, 0x10002},
+			expected: &unicode.RangeTable{
+				R16: []unicode.Range16{{0xfffc, 0xfffe, 2}},
+				R32: []unicode.Range32{{0x10000, 0x10002, 2}},
+			},
+		},
+		{
+			rs: []rune{0xfffe, 0x10000, 0x10002},
+			expected: &unicode.RangeTable{
+				R16: []unicode.Range16{{0xfffe, 0xfffe, 1}},
+				R32: []unicode.Range32{{0x10000, 0x10002, 2}},
+			},
+		},
+		{
+			rs: []rune{0xffff, 0x10000},
+			expected: &unicode.RangeTable{
+				R16: []unicode.Range16{{0xffff, 0xffff, 1}},
+				R32: []unicode.Range32{{0x10000, 0x10000, 1}},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		got := ToRangeTable(Compile(tc.rs))
+		util.Equal(t, true, equalRangeTables(tc.expected, got), "index=%v; got: %#v", i, got)
+	}
+}
+
+func equalRangeTables(a, b *unicode.RangeTable) bool {
+	return slices.Equal(a.R16, b.R16) &&
+		slices.Equal(a.R32, b.R32) &&
+		a.LatinOffset == b.LatinOffset
+}
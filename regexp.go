@@ -0,0 +1,183 @@
+package runes
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"slices"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FromRegexpClass returns the set of runes matched by a node of a parsed
+// regular expression, which must be one of:
+//   - OpCharClass, whose Rune field holds pairs of inclusive ranges.
+//   - OpLiteral with a single rune.
+//   - OpAnyChar or OpAnyCharNotNL.
+//
+// If the FoldCase flag is set, the simple case folding of every rune is also
+// included, as package regexp does for literals. Classes produced by
+// syntax.Parse are already folded, so this only makes a difference for nodes
+// built by hand. The result is optimized with [Compile].
+func FromRegexpClass(re *syntax.Regexp) (MinMaxSet, error) {
+	var b []rune
+	var err error
+	switch re.Op {
+	case syntax.OpCharClass:
+		b, err = pairsBounds(re.Rune)
+	case syntax.OpLiteral:
+		if len(re.Rune) != 1 {
+			return nil, fmt.Errorf("runes: literal of %d runes is not a character class", len(re.Rune))
+		}
+		b, err = pairsBounds([]rune{re.Rune[0], re.Rune[0]})
+	case syntax.OpAnyChar:
+		b = anyBounds
+	case syntax.OpAnyCharNotNL:
+		b = []rune{0, '\n', '\n' + 1, utf8.MaxRune + 1}
+	default:
+		return nil, fmt.Errorf("runes: regexp op %v is not a character class", re.Op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return foldBounds(b, re.Flags), nil
+}
+
+// ToRegexpClass returns the runes of the set as the pairs of inclusive ranges
+// used in the Rune field of a syntax.Regexp with op OpCharClass. Runes outside
+// [0, utf8.MaxRune] are left out.
+func ToRegexpClass(s MinMaxSet) []rune {
+	var pairs []rune
+	for lo, hi := range validRanges(s) {
+		pairs = append(pairs, lo, hi)
+	}
+	return pairs
+}
+
+// RegexpClasses walks a parsed regular expression and returns a set for each
+// node that matches a single rune from a class, which are the nodes with op
+// OpCharClass, OpAnyChar or OpAnyCharNotNL, and the literals of a single rune
+// with the FoldCase flag, like that of `(?i)k`, which match any case of their
+// rune. Each set is the one returned by [FromRegexpClass], and equal classes
+// share the same set. The expression is not modified. It is meant for matching
+// engines that walk the syntax tree, which can look up the set of a node in the
+// returned map instead of scanning its Rune field.
+func RegexpClasses(re *syntax.Regexp) (map[*syntax.Regexp]MinMaxSet, error) {
+	sets := map[*syntax.Regexp]MinMaxSet{}
+	// byClass deduplicates the sets of equal classes
+	byClass := map[string]MinMaxSet{}
+	var walk func(re *syntax.Regexp) error
+	walk = func(re *syntax.Regexp) error {
+		switch {
+		case re.Op == syntax.OpCharClass, re.Op == syntax.OpAnyChar, re.Op == syntax.OpAnyCharNotNL,
+			re.Op == syntax.OpLiteral && len(re.Rune) == 1 && re.Flags&syntax.FoldCase != 0:
+			key := fmt.Sprint(re.Op, re.Flags&syntax.FoldCase, re.Rune)
+			s, ok := byClass[key]
+			if !ok {
+				var err error
+				if s, err = FromRegexpClass(re); err != nil {
+					return err
+				}
+				byClass[key] = s
+			}
+			sets[re] = s
+		}
+		for _, sub := range re.Sub {
+			if err := walk(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(re); err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+// pairsBounds returns the bounds of the union of the given pairs of inclusive
+// ranges, which need not be sorted.
+func pairsBounds(pairs []rune) ([]rune, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("runes: odd number of runes in character class")
+	}
+	ranges := make([][2]rune, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		lo, hi := pairs[i], pairs[i+1]
+		if lo < 0 || lo > hi || hi > utf8.MaxRune {
+			return nil, fmt.Errorf("runes: invalid range %#x-%#x in character class", lo, hi)
+		}
+		ranges = append(ranges, [2]rune{lo, hi})
+	}
+	slices.SortFunc(ranges, func(a, b [2]rune) int { return int(a[0] - b[0]) })
+
+	var b []rune
+	for _, r := range ranges {
+		if n := len(b); n > 0 && r[0] <= b[n-1] {
+			b[n-1] = max(b[n-1], r[1]+1)
+			continue
+		}
+		b = append(b, r[0], r[1]+1)
+	}
+	return b, nil
+}
+
+// foldBounds compiles the set with the given bounds, adding the simple case
+// folding of its runes if `flags` has FoldCase. Only the runes that fold to
+// other runes are looked up, so big classes are cheap.
+func foldBounds(b []rune, flags syntax.Flags) MinMaxSet {
+	if flags&syntax.FoldCase == 0 {
+		return compileBounds(b)
+	}
+	folding := foldingRunes()
+	var rs []rune
+	for i := 0; i+1 < len(b); i += 2 {
+		j, _ := slices.BinarySearch(folding, b[i])
+		for ; j < len(folding) && folding[j] < b[i+1]; j++ {
+			for f := unicode.SimpleFold(folding[j]); f != folding[j]; f = unicode.SimpleFold(f) {
+				rs = append(rs, f)
+			}
+		}
+	}
+	slices.Sort(rs)
+	return compileBounds(combineBounds(b, runesBounds(slices.Compact(rs)), or))
+}
+
+// foldingRunes returns the sorted runes whose simple case folding is another
+// rune. Such runes have a case mapping in unicode.CaseRanges or are cased
+// letters, so only those are checked.
+var foldingRunes = sync.OnceValue(func() []rune {
+	var cased []rune
+	for _, cr := range unicode.CaseRanges {
+		if n := len(cased); n > 0 && cased[n-1] == rune(cr.Lo) {
+			cased[n-1] = rune(cr.Hi) + 1
+			continue
+		}
+		cased = append(cased, rune(cr.Lo), rune(cr.Hi)+1)
+	}
+	cased = combineBounds(cased, tablesBounds(unicode.Upper, unicode.Lower, unicode.Title), or)
+
+	var rs []rune
+	for i := 0; i+1 < len(cased); i += 2 {
+		for r := cased[i]; r < cased[i+1]; r++ {
+			if unicode.SimpleFold(r) != r {
+				rs = append(rs, r)
+			}
+		}
+	}
+	return rs
+})
+
+// runesBounds returns the bounds of the given sorted runes, which must not
+// have duplicates.
+func runesBounds(rs []rune) []rune {
+	var b []rune
+	for _, r := range rs {
+		if n := len(b); n > 0 && b[n-1] == r {
+			b[n-1]++
+			continue
+		}
+		b = append(b, r, r+1)
+	}
+	return b
+}
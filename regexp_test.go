@@ -0,0 +1,175 @@
+package runes
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"slices"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/diegommm/runes/util"
+)
+
+func TestFromRegexpClass(t *testing.T) {
+	t.Parallel()
+	testCases := []string{
+		`[a-z]`,
+		`[^a-z]`,
+		`[\d\s]`,
+		`\pL`,
+		`[\p{Greek}\p{Nd}]`,
+		`(?i)[a-z]`,
+		`(?i)[^k]`,
+		`[^\x00-\x{10FFFF}]`,
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("index=%v", i), func(t *testing.T) {
+			t.Parallel()
+			re, err := syntax.Parse(tc, syntax.Perl)
+			util.MustEqual(t, nil, err, "parse %q", tc)
+			util.MustEqual(t, syntax.OpCharClass, re.Op, "unexpected op")
+			s, err := FromRegexpClass(re)
+			util.MustEqual(t, nil, err, "FromRegexpClass")
+			testPredicateEquivalence(t, s, func(r rune) bool {
+				return inPairs(re.Rune, r)
+			})
+			util.Equal(t, true, slices.Equal(re.Rune, ToRegexpClass(s)),
+				"unexpected class: %v", ToRegexpClass(s))
+		})
+	}
+}
+
+func TestFromRegexpClassOps(t *testing.T) {
+	t.Parallel()
+	const kelvin = 'K'
+	testCases := []struct {
+		re       *syntax.Regexp
+		expected []rune
+	}{
+		{&syntax.Regexp{Op: syntax.OpCharClass, Rune: []rune{'x', 'y', 'a', 'c', 'b', 'd'}},
+			[]rune{'a', 'd', 'x', 'y'}},
+		{&syntax.Regexp{Op: syntax.OpCharClass, Flags: syntax.FoldCase, Rune: []rune{'k', 'k'}},
+			[]rune{'K', 'K', 'k', 'k', kelvin, kelvin}},
+		{&syntax.Regexp{Op: syntax.OpCharClass, Rune: []rune{}}, nil},
+		{&syntax.Regexp{Op: syntax.OpLiteral, Flags: syntax.FoldCase, Rune: []rune{'s'}},
+			[]rune{'S', 'S', 's', 's', 'ſ', 'ſ'}},
+		{&syntax.Regexp{Op: syntax.OpLiteral, Rune: []rune{'s'}}, []rune{'s', 's'}},
+		{&syntax.Regexp{Op: syntax.OpAnyChar}, []rune{0, unicode.MaxRune}},
+		{&syntax.Regexp{Op: syntax.OpAnyCharNotNL}, []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}},
+	}
+
+	for i, tc := range testCases {
+		s, err := FromRegexpClass(tc.re)
+		util.MustEqual(t, nil, err, "index=%v; FromRegexpClass", i)
+		got := ToRegexpClass(s)
+		util.Equal(t, true, slices.Equal(tc.expected, got), "index=%v; unexpected class: %v", i, got)
+	}
+}
+
+func TestToRegexpClassInvalidRunes(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		set      MinMaxSet
+		expected []rune
+	}{
+		{LinearSlice[rune]{-3, 5}, []rune{5, 5}},
+		{Interval[rune]{-10, 'a'}, []rune{0, 'a'}},
+		{RangeSlice[rune]{{'a', 'b'}, {0x10fff0, 0x200000}, {0x300000, 0x300001}}, []rune{'a', 'b', 0x10fff0, utf8.MaxRune}},
+		{LinearSlice[rune]{-1, 0x110000}, nil},
+	}
+	for i, tc := range testCases {
+		got := ToRegexpClass(tc.set)
+		util.Equal(t, true, slices.Equal(tc.expected, got), "index=%v; unexpected class: %v", i, got)
+	}
+}
+
+func TestFoldingRunes(t *testing.T) {
+	t.Parallel()
+	var expected []rune
+	for r := range rune(utf8.MaxRune + 1) {
+		if unicode.SimpleFold(r) != r {
+			expected = append(expected, r)
+		}
+	}
+	util.Equal(t, true, slices.Equal(expected, foldingRunes()), "unexpected folding runes")
+}
+
+func TestFromRegexpClassErrors(t *testing.T) {
+	t.Parallel()
+	testCases := []*syntax.Regexp{
+		{Op: syntax.OpEmptyMatch},
+		{Op: syntax.OpLiteral, Rune: []rune{'a', 'b'}},
+		{Op: syntax.OpLiteral, Rune: []rune{-1}},
+		{Op: syntax.OpCharClass, Rune: []rune{'a'}},
+		{Op: syntax.OpCharClass, Rune: []rune{'b', 'a'}},
+		{Op: syntax.OpCharClass, Rune: []rune{0, unicode.MaxRune + 1}},
+	}
+
+	for i, re := range testCases {
+		_, err := FromRegexpClass(re)
+		util.Equal(t, true, err != nil, "index=%v; expected error", i)
+	}
+}
+
+func TestRegexpClasses(t *testing.T) {
+	t.Parallel()
+	re, err := syntax.Parse(`[a-z]+(?:[0-9]|\pL)*[a-z].x`, syntax.Perl)
+	util.MustEqual(t, nil, err, "parse")
+	sets, err := RegexpClasses(re)
+	util.MustEqual(t, nil, err, "RegexpClasses")
+
+	var n int
+	var walk func(re *syntax.Regexp)
+	walk = func(re *syntax.Regexp) {
+		switch re.Op {
+		case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			n++
+			s, ok := sets[re]
+			util.MustEqual(t, true, ok, "missing set of %v", re)
+			expected, err := FromRegexpClass(re)
+			util.MustEqual(t, nil, err, "FromRegexpClass")
+			util.Equal(t, true, Equal(expected, s), "unexpected set of %v", re)
+		}
+		for _, sub := range re.Sub {
+			walk(sub)
+		}
+	}
+	walk(re)
+	util.Equal(t, 4, n, "unexpected number of classes")
+	util.Equal(t, n, len(sets), "unexpected number of sets")
+
+	_, err = RegexpClasses(&syntax.Regexp{
+		Op:  syntax.OpConcat,
+		Sub: []*syntax.Regexp{{Op: syntax.OpCharClass, Rune: []rune{'a'}}},
+	})
+	util.Equal(t, true, err != nil, "expected error")
+}
+
+func TestRegexpClassesFoldCase(t *testing.T) {
+	t.Parallel()
+	re, err := syntax.Parse(`(?i)k(?-i:x)(?i:ab)`, syntax.Perl)
+	util.MustEqual(t, nil, err, "parse")
+	before := re.String()
+	sets, err := RegexpClasses(re)
+	util.MustEqual(t, nil, err, "RegexpClasses")
+	util.Equal(t, before, re.String(), "expression was modified")
+
+	// only the 'k' is a literal of a single rune with FoldCase
+	util.MustEqual(t, 1, len(sets), "unexpected number of sets")
+	for node, s := range sets {
+		util.Equal(t, syntax.OpLiteral, node.Op, "unexpected op")
+		util.Equal(t, true, Equal(LinearSlice[rune]{'K', 'k', '\u212a'}, s), "unexpected set of 'k'")
+	}
+}
+
+// inPairs reports whether `r` is in the given pairs of inclusive ranges.
+func inPairs(pairs []rune, r rune) bool {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if r >= pairs[i] && r <= pairs[i+1] {
+			return true
+		}
+	}
+	return false
+}
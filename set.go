@@ -1,7 +1,20 @@
 package runes
 
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
 const MaxUint32 = 1<<32 - 1
 
+const (
+	maxUint8  = 1<<8 - 1
+	maxUint16 = 1<<16 - 1
+)
+
 // Set is a set of runes.
 type Set interface {
 	// Contains returns whether the given rune is part of the set.
@@ -15,43 +28,204 @@ type MinMaxSet interface {
 	Max() uint32 // MaxUint32 if Set is empty
 }
 
+// Enumerable is a [Set] that can list its runes.
+type Enumerable interface {
+	Set
+	// All returns an iterator over the runes of the set in ascending order.
+	All() iter.Seq[rune]
+	// Ranges returns an iterator over the maximal intervals [lo, hi] of
+	// consecutive runes of the set, in ascending order.
+	Ranges() iter.Seq2[rune, rune]
+}
+
+// Indexed is a [Set] whose runes can be counted and addressed by their
+// position in ascending order.
+type Indexed interface {
+	Set
+	// Len returns the number of runes in the set.
+	Len() int
+	// Rank returns the number of runes in the set that are smaller than the
+	// given one.
+	Rank(rune) int
+	// Select returns the i-th rune of the set in ascending order, starting at
+	// zero, and true. It returns false if i is out of range.
+	Select(i int) (rune, bool)
+}
+
+// Navigable is a [Set] that can find the runes closest to a given one.
+type Navigable interface {
+	Set
+	// Next returns the smallest rune of the set that is greater than or equal
+	// to the given one, and true. It returns false if there is no such rune.
+	Next(rune) (rune, bool)
+	// Prev returns the biggest rune of the set that is less than or equal to
+	// the given one, and true. It returns false if there is no such rune.
+	Prev(rune) (rune, bool)
+}
+
 // RuneT are the types with which runes of different width can be represented
 // without losing information.
 type RuneT interface {
 	uint8 | uint16 | uint32 | rune
 }
 
-// Union is a [Set] that represents the union of its elements. The first element
-// must have the smallest rune, and the last element the biggest.
+// Union is a [Set] that represents the union of its elements. The first
+// non-empty element must have the smallest rune, and the last non-empty element
+// the biggest. Its elements may overlap, so `Contains` checks them one by one,
+// which is linear in the number of elements. Use [SortedUnion] for elements
+// that do not overlap, which is searched in logarithmic time.
 type Union[T MinMaxSet] []T
 
 func (x Union[T]) Contains(r rune) bool {
-	if len(x) == 0 { // || uint32(r) > x[len(x)-1].Max() {
+	if len(x) == 0 {
+		return false
+	}
+	// Only the first and last elements are used to reject runes out of bounds,
+	// unless they are empty, since Min and Max walk the union to skip them.
+	u := uint32(r)
+	if m := x[0].Min(); m != MaxUint32 && u < m {
+		return false
+	}
+	if last := x[len(x)-1]; last.Min() != MaxUint32 && u > last.Max() {
 		return false
 	}
 	for i := range x {
 		if x[i].Contains(r) {
 			return true
 		}
-		//if uint32(r) < x[i].Min() {
-		//return false
-		//}
 	}
 	return false
 }
 
+// Min and Max skip empty elements, which report MaxUint32 as their bounds.
 func (x Union[T]) Min() uint32 {
-	if len(x) == 0 {
-		return MaxUint32
+	for i := range x {
+		if m := x[i].Min(); m != MaxUint32 {
+			return m
+		}
 	}
-	return x[0].Min()
+	return MaxUint32
 }
 
 func (x Union[T]) Max() uint32 {
-	if len(x) == 0 {
+	for i := len(x) - 1; i >= 0; i-- {
+		if x[i].Min() != MaxUint32 {
+			return x[i].Max()
+		}
+	}
+	return MaxUint32
+}
+
+// ErrUnsortedUnion is returned by [NewSortedUnion] when its members are not
+// sorted or overlap.
+var ErrUnsortedUnion = errors.New("runes: union members not sorted")
+
+// NewSortedUnion returns a [SortedUnion] of the given members, which must be
+// sorted in ascending order and must not overlap, that is, each member must
+// only have runes bigger than those of the previous member. Empty members are
+// discarded.
+func NewSortedUnion[T MinMaxSet](members ...T) (SortedUnion[T], error) {
+	res := make([]T, 0, len(members))
+	for i, m := range members {
+		if m.Min() == MaxUint32 || m.Min() > m.Max() {
+			continue
+		}
+		if n := len(res); n > 0 && res[n-1].Max() >= m.Min() {
+			return SortedUnion[T]{}, fmt.Errorf("%w: member %d starts at 0x%x, before 0x%x",
+				ErrUnsortedUnion, i, m.Min(), res[n-1].Max())
+		}
+		res = append(res, m)
+	}
+	return newSortedUnion(res), nil
+}
+
+// MustSortedUnion is like [NewSortedUnion] but panics if the members are not
+// sorted or overlap.
+func MustSortedUnion[T MinMaxSet](members ...T) SortedUnion[T] {
+	x, err := NewSortedUnion(members...)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// newSortedUnion returns a SortedUnion of the given members without checking
+// them. They must be sorted, must not overlap and must not be empty.
+func newSortedUnion[T MinMaxSet](members []T) SortedUnion[T] {
+	x := SortedUnion[T]{
+		elems:  members,
+		bounds: make([]uint32, 2*len(members)),
+	}
+	for i, m := range members {
+		x.bounds[2*i], x.bounds[2*i+1] = m.Min(), m.Max()
+	}
+	x.ranks = cumulative(len(members), func(i int) int { return setLen(members[i]) })
+	return x
+}
+
+// SortedUnion is a [Set] that represents the union of its members, which are
+// sorted and do not overlap. The bounds of its members are kept in a separate
+// slice, so that `Contains` makes a binary search over them and checks at most
+// one member. It can only be created with [NewSortedUnion] or
+// [MustSortedUnion], which guarantee that it is valid.
+type SortedUnion[T MinMaxSet] struct {
+	elems []T
+	// bounds has the Min and Max of each member, so it is sorted in
+	// ascending order
+	bounds []uint32
+	// ranks has the number of runes before each member, followed by the
+	// number of runes of the union
+	ranks []uint32
+}
+
+func (x SortedUnion[T]) Contains(r rune) bool {
+	u := uint32(r)
+	if len(x.bounds) == 0 || u < x.bounds[0] || u > x.bounds[len(x.bounds)-1] {
+		return false
+	}
+	i := x.search(u)
+	return u >= x.bounds[2*i] && x.elems[i].Contains(r)
+}
+
+// search returns the index of the first member whose Max is greater than or
+// equal to `u`, or the number of members if there is none.
+func (x SortedUnion[T]) search(u uint32) int {
+	return searchBounds(x.bounds, u)
+}
+
+// searchBounds returns the index of the first pair of sorted min and max
+// `bounds` whose max is greater than or equal to `u`, or the number of pairs if
+// there is none.
+func searchBounds(bounds []uint32, u uint32) int {
+	i, j := 0, len(bounds)/2
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if bounds[2*h+1] < u {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
+
+func (x SortedUnion[T]) Min() uint32 {
+	if len(x.bounds) == 0 {
 		return MaxUint32
 	}
-	return x[len(x)-1].Max()
+	return x.bounds[0]
+}
+
+func (x SortedUnion[T]) Max() uint32 {
+	if len(x.bounds) == 0 {
+		return MaxUint32
+	}
+	return x.bounds[len(x.bounds)-1]
+}
+
+// Members returns the members of the union, which must not be modified.
+func (x SortedUnion[T]) Members() []T {
+	return x.elems
 }
 
 // LinearSlice is a [Set] that uses a linear search in its `Contains` method.
@@ -128,6 +302,129 @@ func (x BinarySlice[T]) Max() uint32 {
 	return uint32(x[len(x)-1])
 }
 
+// NewRangeSlice creates a [RangeSlice] from the given runes, which must be
+// sorted in ascending order and fit in T.
+func NewRangeSlice[T RuneT](rs []rune) RangeSlice[T] {
+	var x RangeSlice[T]
+	for i := 0; i < len(rs); i++ {
+		if n := len(x); n > 0 && rune(x[n-1].To)+1 == rs[i] {
+			x[n-1].To = T(rs[i])
+			continue
+		}
+		x = append(x, Interval[T]{T(rs[i]), T(rs[i])})
+	}
+	return x
+}
+
+// RangeSlice is a [Set] of ranges that uses a binary search in its `Contains`
+// method. Its elements must be valid intervals sorted in ascending order, and
+// must not overlap. Its `Len`, `Rank` and `Select` methods add up the lengths
+// of its elements, so they take linear time; a [SortedUnion] of the same
+// intervals keeps cumulative counts instead.
+type RangeSlice[T RuneT] []Interval[T]
+
+func (x RangeSlice[T]) Contains(r rune) bool {
+	return len(x) > 0 &&
+		r >= rune(x[0].From) &&
+		r <= rune(x[len(x)-1].To) &&
+		x.containsSlow(r)
+}
+
+func (x RangeSlice[T]) containsSlow(r rune) bool {
+	i := x.search(r)
+	return i < len(x) && rune(x[i].From) <= r
+}
+
+// search returns the index of the first range that ends at or after `r`, or
+// the length of the slice if there is none.
+func (x RangeSlice[T]) search(r rune) int {
+	return searchRanges(x, r, func(v Interval[T]) rune { return rune(v.To) })
+}
+
+func (x RangeSlice[T]) Min() uint32 {
+	if len(x) == 0 {
+		return MaxUint32
+	}
+	return uint32(x[0].From)
+}
+
+func (x RangeSlice[T]) Max() uint32 {
+	if len(x) == 0 {
+		return MaxUint32
+	}
+	return uint32(x[len(x)-1].To)
+}
+
+// NewStridedRanges creates a [StridedRanges] from the given runes, which must
+// be sorted in ascending order and fit in T. The runes are split in runs of
+// equally spaced runes like [Compile] does.
+func NewStridedRanges[T RuneT](rs []rune) StridedRanges[T] {
+	return newStridedRanges[T](splitAtoms(rs))
+}
+
+func newStridedRanges[T RuneT](atoms []atom) StridedRanges[T] {
+	x := make(StridedRanges[T], len(atoms))
+	for i, a := range atoms {
+		x[i] = Uniform[T]{T(a.lo), T(a.hi), T(a.stride)}
+	}
+	return x
+}
+
+// StridedRanges is a [Set] of strided ranges, like the Range16 and Range32 of
+// a *unicode.RangeTable, that uses a binary search in its `Contains` method.
+// Its elements must be valid [Uniform] sets sorted in ascending order, and
+// must not overlap. Like those of [RangeSlice], its `Len`, `Rank` and `Select`
+// methods take linear time.
+type StridedRanges[T RuneT] []Uniform[T]
+
+func (x StridedRanges[T]) Contains(r rune) bool {
+	return len(x) > 0 &&
+		r >= rune(x[0].Lo) &&
+		r <= rune(x[len(x)-1].Hi) &&
+		x.containsSlow(r)
+}
+
+func (x StridedRanges[T]) containsSlow(r rune) bool {
+	i := x.search(r)
+	return i < len(x) && x[i].Contains(r)
+}
+
+// search returns the index of the first range that ends at or after `r`, or
+// the length of the slice if there is none.
+func (x StridedRanges[T]) search(r rune) int {
+	return searchRanges(x, r, func(v Uniform[T]) rune { return rune(v.Hi) })
+}
+
+func (x StridedRanges[T]) Min() uint32 {
+	if len(x) == 0 {
+		return MaxUint32
+	}
+	return uint32(x[0].Lo)
+}
+
+func (x StridedRanges[T]) Max() uint32 {
+	if len(x) == 0 {
+		return MaxUint32
+	}
+	return uint32(x[len(x)-1].Hi)
+}
+
+// searchRanges returns the index of the first element of `x` whose last rune,
+// as returned by `hi`, is greater than or equal to `r`, or the length of `x`
+// if there is none. The elements must be sorted.
+func searchRanges[E any](x []E, r rune, hi func(E) rune) int {
+	i, j := 0, len(x)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if hi(x[h]) < r {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
+
 // Interval is the set of runes in the interval [From, To].
 type Interval[T RuneT] struct {
 	From, To T // `From` must be less than or equal to `To`
@@ -146,7 +443,7 @@ func (x Interval[T]) Max() uint32 {
 }
 
 // Uniform is a [Set] that contains the runes uniformly distributed `Stride`
-// apart from each other, starting at `First` and ending in `Hi`.
+// apart from each other, starting at `Lo` and ending in `Hi`.
 type Uniform[T RuneT] struct {
 	Lo     T
 	Hi     T // must be >= Lo
@@ -155,7 +452,7 @@ type Uniform[T RuneT] struct {
 
 func (x Uniform[T]) Contains(r rune) bool {
 	v, stride := uint32(r-rune(x.Lo)), uint32(x.Stride)
-	return v < uint32(x.Hi) && stride > 0 && v%stride == 0
+	return v <= uint32(x.Hi-x.Lo) && stride > 0 && v%stride == 0
 }
 
 func (x Uniform[T]) Min() uint32 {
@@ -189,7 +486,7 @@ func writeBitmapHeader(bm []byte, rs []rune) {
 	hdr[2] &= lsb5 // ensure an incorrect min rune does not break encoding
 	// encode the highest 1 in the last byte corresponding to Max()
 	posOfHighestBitInLastByte := byte(uint32(rs[len(rs)-1]-rs[0]) & 7)
-	hdr[2] |= posOfHighestBitInLastByte << lsb5
+	hdr[2] |= posOfHighestBitInLastByte << bmPosShift
 }
 
 func writeBitmapBody(bmBody []byte, rs []rune) {
@@ -217,6 +514,9 @@ const bmHdrLen = 3
 
 const lsb5 = 0b00011111
 
+// bmPosShift is the offset of the 3 B bits in the last byte of the header.
+const bmPosShift = 5
+
 func (x Bitmap) Contains(r rune) bool {
 	if len(x) < bmHdrLen {
 		return false
@@ -237,8 +537,8 @@ func (x Bitmap) Max() uint32 {
 	switch {
 	case len(x) > bmHdrLen:
 		return uint32(bmDecodeMinRune(x[0], x[1], x[2]&lsb5)) +
-			uint32(len(x)<<3) +
-			uint32(x[2]>>lsb5)
+			uint32(len(x)-bmHdrLen-1)<<3 +
+			uint32(x[2]>>bmPosShift)
 	case len(x) == bmHdrLen:
 		return uint32(bmDecodeMinRune(x[0], x[1], x[2]&lsb5))
 	default:
@@ -246,6 +546,568 @@ func (x Bitmap) Max() uint32 {
 	}
 }
 
+// NewTwoLevel creates a [TwoLevel] from the given runes, which must be sorted
+// in ascending order.
+func NewTwoLevel(rs []rune) TwoLevel {
+	if len(rs) == 0 {
+		return TwoLevel{}
+	}
+	first := uint32(rs[0]) >> blockBits
+	blocks := make([]uint64, uint32(rs[len(rs)-1])>>blockBits-first+1)
+	for _, r := range rs {
+		blocks[uint32(r)>>blockBits-first] |= 1 << (uint32(r) & blockMask)
+	}
+
+	x := TwoLevel{First: first, Index: make([]uint16, len(blocks))}
+	seen := make(map[uint64]uint16)
+	for i, leaf := range blocks {
+		j, ok := seen[leaf]
+		if !ok {
+			j = uint16(len(x.Leaves))
+			seen[leaf] = j
+			x.Leaves = append(x.Leaves, leaf)
+		}
+		x.Index[i] = j
+	}
+	x.Ranks = x.ranks()
+	return x
+}
+
+// TwoLevel is a [Set] that splits runes in blocks of 64 and stores a bitmap
+// for each block, where identical bitmaps are stored only once. The high bits
+// of a rune select its block in `Index`, which holds the position in `Leaves`
+// of the bitmap of the block, and the low bits select the bit of the rune in
+// that bitmap. This makes `Contains` take two array lookups regardless of the
+// runes in the set, which is best for big sets with runes scattered over wide
+// ranges, and blocks that are empty or full are shared by the whole set.
+type TwoLevel struct {
+	// First is the block of the first rune, which is the rune shifted right
+	// by 6 bits.
+	First uint32
+	// Index holds the position in Leaves of the bitmap of each block from
+	// First. Its first and last blocks must not be empty.
+	Index []uint16
+	// Leaves are the distinct bitmaps of the blocks, where bit `i` means that
+	// the rune at `i` from the start of the block is in the set.
+	Leaves []uint64
+	// Ranks has the number of runes before each block of Index, followed by
+	// the number of runes of the set. It is optional, and makes `Len`, `Rank`
+	// and `Select` not depend on the number of blocks. [NewTwoLevel] and
+	// decoding set it.
+	Ranks []uint32
+}
+
+// TwoLevel and SparseBitmap split runes in blocks of 64, so that the bitmap of
+// a block is a uint64. The block of a rune is the rune shifted right by
+// blockBits, and its bit in the bitmap is the rune masked with blockMask.
+const (
+	blockBits = 6
+	blockMask = 1<<blockBits - 1
+)
+
+func (x TwoLevel) Contains(r rune) bool {
+	i := uint32(r)>>blockBits - x.First
+	if i >= uint32(len(x.Index)) {
+		return false
+	}
+	j := int(x.Index[i])
+	return j < len(x.Leaves) && x.Leaves[j]>>(uint32(r)&blockMask)&1 != 0
+}
+
+// leaf returns the bitmap of the i-th block of Index.
+func (x TwoLevel) leaf(i int) uint64 {
+	if j := int(x.Index[i]); j < len(x.Leaves) {
+		return x.Leaves[j]
+	}
+	return 0
+}
+
+func (x TwoLevel) Min() uint32 {
+	if len(x.Index) == 0 {
+		return MaxUint32
+	}
+	return x.First<<blockBits + uint32(bits.TrailingZeros64(x.leaf(0)))
+}
+
+func (x TwoLevel) Max() uint32 {
+	if len(x.Index) == 0 {
+		return MaxUint32
+	}
+	n := len(x.Index) - 1
+	return (x.First+uint32(n))<<blockBits + uint32(63-bits.LeadingZeros64(x.leaf(n)))
+}
+
+// NewSparseBitmap creates a [SparseBitmap] from the given runes, which must be
+// sorted in ascending order.
+func NewSparseBitmap(rs []rune) SparseBitmap {
+	var x SparseBitmap
+	for _, r := range rs {
+		block := uint16(uint32(r) >> blockBits)
+		if n := len(x.Blocks); n == 0 || x.Blocks[n-1] != block {
+			x.Blocks = append(x.Blocks, block)
+			x.Words = append(x.Words, 0)
+		}
+		x.Words[len(x.Words)-1] |= 1 << (uint32(r) & blockMask)
+	}
+	x.Ranks = x.ranks()
+	return x
+}
+
+// SparseBitmap is a [Set] that splits runes in blocks of 64 and stores a
+// bitmap only for the blocks that have runes, with a binary search over the
+// directory of blocks in its `Contains` method. Unlike [Bitmap], it does not
+// take space for the gaps between runes, which makes it best for sets with
+// small clusters of runes far from each other.
+type SparseBitmap struct {
+	// Blocks are the blocks that have runes, in ascending order. The block
+	// of a rune is the rune shifted right by 6 bits.
+	Blocks []uint16
+	// Words are the bitmaps of each of Blocks, where bit `i` means that the
+	// rune at `i` from the start of the block is in the set. They must not be
+	// zero, and must have the same length as Blocks.
+	Words []uint64
+	// Ranks has the number of runes before each block, followed by the
+	// number of runes of the set. It is optional, and makes `Len`, `Rank` and
+	// `Select` not depend on the number of blocks. [NewSparseBitmap] and
+	// decoding set it.
+	Ranks []uint32
+}
+
+func (x SparseBitmap) Contains(r rune) bool {
+	block := uint32(r) >> blockBits
+	return len(x.Blocks) > 0 &&
+		block >= uint32(x.Blocks[0]) &&
+		block <= uint32(x.Blocks[len(x.Blocks)-1]) &&
+		x.containsSlow(r)
+}
+
+func (x SparseBitmap) containsSlow(r rune) bool {
+	block := uint32(r) >> blockBits
+	i := x.search(block)
+	return i < len(x.Blocks) && uint32(x.Blocks[i]) == block &&
+		x.word(i)>>(uint32(r)&blockMask)&1 != 0
+}
+
+// search returns the index of the first block that is greater than or equal
+// to `block`, or the length of Blocks if there is none.
+func (x SparseBitmap) search(block uint32) int {
+	if block > maxUint16 {
+		return len(x.Blocks)
+	}
+	return searchRanges(x.Blocks, rune(block), func(v uint16) rune { return rune(v) })
+}
+
+// word returns the bitmap of the i-th block.
+func (x SparseBitmap) word(i int) uint64 {
+	if i < len(x.Words) {
+		return x.Words[i]
+	}
+	return 0
+}
+
+func (x SparseBitmap) Min() uint32 {
+	if len(x.Blocks) == 0 {
+		return MaxUint32
+	}
+	return uint32(x.Blocks[0])<<blockBits + uint32(bits.TrailingZeros64(x.word(0)))
+}
+
+func (x SparseBitmap) Max() uint32 {
+	if len(x.Blocks) == 0 {
+		return MaxUint32
+	}
+	n := len(x.Blocks) - 1
+	return uint32(x.Blocks[n])<<blockBits + uint32(63-bits.LeadingZeros64(x.word(n)))
+}
+
+// NewRoaring creates a [Roaring] from the given runes, which must be sorted in
+// ascending order.
+func NewRoaring(rs []rune) Roaring {
+	var x Roaring
+	for i := 0; i < len(rs); {
+		key := uint32(rs[i]) >> roaringBits
+		j := i + 1
+		for j < len(rs) && uint32(rs[j])>>roaringBits == key {
+			j++
+		}
+		x.Keys = append(x.Keys, uint16(key))
+		x.Containers = append(x.Containers, newRoaringContainer(rs[i:j]))
+		i = j
+	}
+	x.Ranks = x.ranks()
+	return x
+}
+
+// newRoaringContainer returns the smallest container for the given runes,
+// which must be sorted in ascending order and be in the same chunk.
+func newRoaringContainer(rs []rune) RoaringContainer {
+	runs := 1
+	for i := 1; i < len(rs); i++ {
+		if rs[i] != rs[i-1]+1 {
+			runs++
+		}
+	}
+
+	var c RoaringContainer
+	switch {
+	case 2*runs <= len(rs) && 2*runs < roaringWords:
+		c.Kind = RoaringRuns
+		for i, r := range rs {
+			v := uint16(r & roaringMask)
+			if i > 0 && r == rs[i-1]+1 {
+				c.Data[len(c.Data)-1] = v
+			} else {
+				c.Data = append(c.Data, v, v)
+			}
+		}
+	case len(rs) < roaringWords:
+		c.Kind = RoaringArray
+		c.Data = make([]uint16, len(rs))
+		for i, r := range rs {
+			c.Data[i] = uint16(r & roaringMask)
+		}
+	default:
+		c.Kind = RoaringBitmap
+		c.Data = make([]uint16, roaringWords)
+		for _, r := range rs {
+			v := r & roaringMask
+			c.Data[v>>4] |= 1 << (v & 15)
+		}
+	}
+	return c
+}
+
+// Roaring is a [Set] that splits runes in chunks of 4096, like Roaring bitmaps
+// do, and stores the runes of each chunk in the container that takes the least
+// space for them: a sorted array for a few scattered runes, runs for runes in
+// long ranges, and a bitmap for the rest. This gives a size close to the
+// smallest of the other representations for sets of any density, while keeping
+// lookups to a binary search over at most 272 chunks and a lookup in the
+// container, which is constant time for bitmaps.
+type Roaring struct {
+	// Keys are the chunks that have runes, in ascending order. The chunk of a
+	// rune is the rune shifted right by 12 bits.
+	Keys []uint16
+	// Containers hold the runes of each of Keys. They must not be empty, and
+	// must have the same length as Keys.
+	Containers []RoaringContainer
+	// Ranks has the number of runes before each container, followed by the
+	// number of runes of the set. It is optional, and makes `Len`, `Rank` and
+	// `Select` not depend on the number of containers. [NewRoaring] and
+	// decoding set it.
+	Ranks []uint32
+}
+
+// RoaringKind is the kind of a [RoaringContainer].
+type RoaringKind uint8
+
+const (
+	// RoaringArray containers have the sorted offsets of their runes.
+	RoaringArray RoaringKind = iota
+	// RoaringBitmap containers have a bitmap of 256 uint16 words, where bit
+	// `i` of word `j` means that the rune at offset `j*16+i` is in the set.
+	RoaringBitmap
+	// RoaringRuns containers have the first and last offsets of each of their
+	// ranges of runes, in ascending order.
+	RoaringRuns
+)
+
+// RoaringContainer holds the runes of a chunk of a [Roaring], as offsets from
+// the first rune of the chunk.
+type RoaringContainer struct {
+	Kind RoaringKind
+	Data []uint16
+}
+
+const (
+	roaringBits = 12
+	roaringMask = 1<<roaringBits - 1
+	// roaringWords is the number of words of a bitmap container, and the
+	// maximum number of words of the other containers.
+	roaringWords = 1 << roaringBits / 16
+)
+
+func (x Roaring) Contains(r rune) bool {
+	key := uint32(r) >> roaringBits
+	return len(x.Keys) > 0 &&
+		key >= uint32(x.Keys[0]) &&
+		key <= uint32(x.Keys[len(x.Keys)-1]) &&
+		x.containsSlow(r)
+}
+
+func (x Roaring) containsSlow(r rune) bool {
+	key := uint32(r) >> roaringBits
+	i := x.search(key)
+	return i < len(x.Keys) && uint32(x.Keys[i]) == key &&
+		x.container(i).contains(uint16(r&roaringMask))
+}
+
+// search returns the index of the first key that is greater than or equal to
+// `key`, or the length of Keys if there is none.
+func (x Roaring) search(key uint32) int {
+	if key > maxUint16 {
+		return len(x.Keys)
+	}
+	return searchRanges(x.Keys, rune(key), func(v uint16) rune { return rune(v) })
+}
+
+// container returns the i-th container.
+func (x Roaring) container(i int) RoaringContainer {
+	if i < len(x.Containers) {
+		return x.Containers[i]
+	}
+	return RoaringContainer{}
+}
+
+func (x Roaring) Min() uint32 {
+	if len(x.Keys) == 0 {
+		return MaxUint32
+	}
+	return uint32(x.Keys[0])<<roaringBits + uint32(x.container(0).min())
+}
+
+func (x Roaring) Max() uint32 {
+	if len(x.Keys) == 0 {
+		return MaxUint32
+	}
+	n := len(x.Keys) - 1
+	return uint32(x.Keys[n])<<roaringBits + uint32(x.container(n).max())
+}
+
+func (c RoaringContainer) contains(v uint16) bool {
+	switch c.Kind {
+	case RoaringBitmap:
+		i := int(v >> 4)
+		return i < len(c.Data) && c.Data[i]>>(v&15)&1 != 0
+	case RoaringRuns:
+		i := c.searchRun(v)
+		return i < len(c.Data) && c.Data[i] <= v
+	default:
+		_, found := slices.BinarySearch(c.Data, v)
+		return found
+	}
+}
+
+// searchRun returns the index in Data of the first run of a RoaringRuns
+// container that ends at or after `v`, or the length of Data if there is none.
+func (c RoaringContainer) searchRun(v uint16) int {
+	i, j := 0, len(c.Data)/2
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if c.Data[2*h+1] < v {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return 2 * i
+}
+
+// min returns the smallest offset of the container, which must not be empty.
+func (c RoaringContainer) min() uint16 {
+	if c.Kind == RoaringBitmap {
+		for i, w := range c.Data {
+			if w != 0 {
+				return uint16(i)<<4 + uint16(bits.TrailingZeros16(w))
+			}
+		}
+	}
+	if len(c.Data) == 0 {
+		return 0
+	}
+	return c.Data[0]
+}
+
+// max returns the biggest offset of the container, which must not be empty.
+func (c RoaringContainer) max() uint16 {
+	if c.Kind == RoaringBitmap {
+		for i := len(c.Data) - 1; i >= 0; i-- {
+			if w := c.Data[i]; w != 0 {
+				return uint16(i)<<4 + uint16(15-bits.LeadingZeros16(w))
+			}
+		}
+	}
+	if len(c.Data) == 0 {
+		return 0
+	}
+	return c.Data[len(c.Data)-1]
+}
+
+// NewMixedUnion creates a [MixedUnion] from the given runes, grouping them in
+// segments like [Compile] does. The runes need not be sorted, and both
+// duplicates and values outside [0, utf8.MaxRune] are ignored.
+func NewMixedUnion(rs []rune, opts ...Option) MixedUnion {
+	return newMixedUnion(newCompileConfig(opts).segment(splitAtoms(normalizeRunes(rs))))
+}
+
+// MixedUnion is a [Set] made of sorted segments of different kinds, which are
+// stored in flat slices instead of as members of a [SortedUnion]. Its
+// `Contains` method makes a binary search over the bounds of the segments and
+// then a switch on the kind of the segment found, so it needs no interface
+// calls. This makes it best for sets with a mix of dense and sparse regions.
+type MixedUnion struct {
+	// Kinds are the kinds of each segment.
+	Kinds []MixedKind
+	// Bounds are the first and last runes of each segment, which must be in
+	// the set. Segments must be sorted in ascending order and must not
+	// overlap.
+	Bounds []uint32
+	// Args have an argument for each segment that depends on its kind: the
+	// stride of MixedStrided segments, or the index of the first element in
+	// Runes or Words of MixedSlice and MixedBitmap segments. It is zero for
+	// MixedInterval segments.
+	Args []uint32
+	// Runes are the runes of all the MixedSlice segments, in ascending order.
+	Runes []uint32
+	// Words are the bitmaps of all the MixedBitmap segments, where bit `i` of
+	// word `j` of a segment means that the rune at `j*64+i` from its first
+	// rune is in the set.
+	Words []uint64
+	// Ranks has the number of runes before each segment, followed by the
+	// number of runes of the set. It is optional, and makes `Len`, `Rank` and
+	// `Select` not depend on the number of segments. [NewMixedUnion] and
+	// decoding set it.
+	Ranks []uint32
+}
+
+// MixedKind is the kind of a segment of a [MixedUnion].
+type MixedKind uint8
+
+const (
+	// MixedInterval segments have all the runes between their bounds.
+	MixedInterval MixedKind = iota
+	// MixedStrided segments have the runes between their bounds that are a
+	// multiple of their stride away from their first rune.
+	MixedStrided
+	// MixedSlice segments have their runes in Runes.
+	MixedSlice
+	// MixedBitmap segments have a bitmap of their runes in Words.
+	MixedBitmap
+)
+
+func (x MixedUnion) Contains(r rune) bool {
+	u := uint32(r)
+	if len(x.Bounds) == 0 || u < x.Bounds[0] || u > x.Bounds[len(x.Bounds)-1] {
+		return false
+	}
+	i := searchBounds(x.Bounds, u)
+	if i >= x.segments() || u < x.Bounds[2*i] {
+		return false
+	}
+	lo, a := x.Bounds[2*i], x.arg(i)
+	switch x.Kinds[i] {
+	case MixedInterval:
+		return true
+	case MixedStrided:
+		return a > 0 && (u-lo)%a == 0
+	case MixedSlice:
+		// u cannot be more than u-lo runes after the first of the segment
+		_, found := slices.BinarySearch(x.runes(a, u-lo+1), u)
+		return found
+	case MixedBitmap:
+		off := u - lo
+		return x.word(int(a)+int(off>>blockBits))>>(off&blockMask)&1 != 0
+	}
+	return false
+}
+
+// segments returns the number of segments, which is the length of Kinds
+// unless Bounds are shorter.
+func (x MixedUnion) segments() int {
+	return min(len(x.Kinds), len(x.Bounds)/2)
+}
+
+func (x MixedUnion) arg(i int) uint32 {
+	if i < len(x.Args) {
+		return x.Args[i]
+	}
+	return 0
+}
+
+// runes returns at most `n` runes of Runes from index `i`.
+func (x MixedUnion) runes(i, n uint32) []uint32 {
+	i = min(i, uint32(len(x.Runes)))
+	return x.Runes[i : i+min(n, uint32(len(x.Runes))-i)]
+}
+
+func (x MixedUnion) word(i int) uint64 {
+	if uint(i) < uint(len(x.Words)) {
+		return x.Words[i]
+	}
+	return 0
+}
+
+func (x MixedUnion) Min() uint32 {
+	if len(x.Bounds) == 0 {
+		return MaxUint32
+	}
+	return x.Bounds[0]
+}
+
+func (x MixedUnion) Max() uint32 {
+	if len(x.Bounds) == 0 {
+		return MaxUint32
+	}
+	return x.Bounds[len(x.Bounds)-1]
+}
+
+// segment returns the i-th segment as a set, which is empty if there is no
+// such segment.
+func (x MixedUnion) segment(i int) MinMaxSet {
+	if i >= x.segments() {
+		return BinarySlice[uint32](nil)
+	}
+	lo, hi, a := x.Bounds[2*i], x.Bounds[2*i+1], x.arg(i)
+	switch x.Kinds[i] {
+	case MixedInterval:
+		return Interval[uint32]{lo, hi}
+	case MixedStrided:
+		return Uniform[uint32]{lo, hi, a}
+	case MixedSlice:
+		// the last rune of the segment is at most hi-lo runes after the first
+		rs := x.runes(a, hi-lo+1)
+		n, _ := slices.BinarySearch(rs, hi)
+		return BinarySlice[uint32](rs[:min(n+1, len(rs))])
+	case MixedBitmap:
+		start := min(int(a), len(x.Words))
+		end := min(start+int((hi-lo)>>blockBits)+1, len(x.Words))
+		return wordBitmap{lo, x.Words[start:end]}
+	}
+	return BinarySlice[uint32](nil)
+}
+
+// wordBitmap is a bitmap of the runes from `lo`, where bit `i` of word `j`
+// means that the rune at `j*64+i` from `lo` is in the set. It is a view of a
+// MixedBitmap segment.
+type wordBitmap struct {
+	lo    uint32
+	words []uint64
+}
+
+func (x wordBitmap) Contains(r rune) bool {
+	off := uint32(r) - x.lo
+	i := off >> blockBits
+	return i < uint32(len(x.words)) && x.words[i]>>(off&blockMask)&1 != 0
+}
+
+func (x wordBitmap) Min() uint32 {
+	for i, w := range x.words {
+		if w != 0 {
+			return x.lo + uint32(i)<<blockBits + uint32(bits.TrailingZeros64(w))
+		}
+	}
+	return MaxUint32
+}
+
+func (x wordBitmap) Max() uint32 {
+	for i := len(x.words) - 1; i >= 0; i-- {
+		if w := x.words[i]; w != 0 {
+			return x.lo + uint32(i)<<blockBits + uint32(63-bits.LeadingZeros64(w))
+		}
+	}
+	return MaxUint32
+}
+
 // ceilDiv performs the integer division of two uint32, rounding to the next
 // (bigger) integer.
 func ceilDiv(dividend, divisor uint32) uint32 {
@@ -2,6 +2,7 @@ package runes
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"iter"
 	"slices"
@@ -12,9 +13,7 @@ import (
 )
 
 const (
-	maxUint8  = 1<<8 - 1
-	maxUint16 = 1<<16 - 1
-	maxInt32  = 1<<31 - 1
+	maxInt32 = 1<<31 - 1
 
 	maxRune = '\U0010FFFF'
 )
@@ -68,36 +67,9 @@ var (
 )
 
 func newLowestSliceSet(linear bool) func([]rune) Set {
-	// could make this much simpler, just playing around with generics tbh
 	return func(rs []rune) Set {
-		if len(rs) == 0 {
-			return newSliceSet[uint8](true, nil)
-		}
-		last := uint32(rs[len(rs)-1])
-		switch {
-		case last < 1<<8:
-			return newSliceSet[uint8](linear, reduceWidth[uint8](rs))
-		case last < 1<<16:
-			return newSliceSet[uint16](linear, reduceWidth[uint16](rs))
-		default:
-			return newSliceSet[rune](linear, rs)
-		}
-	}
-}
-
-func newSliceSet[T RuneT](linear bool, rs []T) Set {
-	if linear {
-		return LinearSlice[T](rs)
-	}
-	return BinarySlice[T](rs)
-}
-
-func reduceWidth[T uint8 | uint16](rs []rune) []T {
-	res := make([]T, len(rs))
-	for i := range rs {
-		res[i] = T(rs[i])
+		return newNarrowSlice(linear, rs)
 	}
-	return res
 }
 
 func testSlices(t *testing.T, f func([]rune) Set) {
@@ -125,6 +97,68 @@ func testSlices(t *testing.T, f func([]rune) Set) {
 	}.run(t)
 }
 
+func TestRangeSlice(t *testing.T) {
+	t.Parallel()
+	someRunes := []rune{1, 2, 3, 99, 410, 411, maxUint16 + 1}
+	setTestCases{
+		{
+			set:         NewRangeSlice[uint8](nil),
+			notContains: util.Seq(-1, utf8.MaxRune, 1),
+		},
+		{
+			set:         NewRangeSlice[rune](someRunes),
+			contains:    runes(someRunes...),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), runes(someRunes...)),
+		},
+		{
+			// adjacent ranges
+			set:         RangeSlice[uint16]{{1, 2}, {3, 3}, {9, maxUint16}},
+			contains:    util.Concat(util.Seq(1, 3, 1), util.Seq(9, maxUint16, 1)),
+			notContains: util.Concat(runes(-1, 0, 4, 8), util.Seq(maxUint16+1, utf8.MaxRune, 1)),
+		},
+	}.run(t)
+}
+
+func TestNewRangeSlice(t *testing.T) {
+	t.Parallel()
+	got := NewRangeSlice[uint16]([]rune{1, 2, 3, 5, 0x100, 0x101})
+	util.Equal(t, "runes.RangeSlice[uint16]{{From: 0x1, To: 0x3}, {From: 0x5, To: 0x5}, {From: 0x100, To: 0x101}}",
+		got.GoString(), "unexpected ranges")
+	util.Equal(t, 0, len(NewRangeSlice[uint8](nil)), "unexpected length")
+}
+
+func TestStridedRanges(t *testing.T) {
+	t.Parallel()
+	someRunes := []rune{1, 2, 3, 99, 410, 412, 414, maxUint16 + 1}
+	setTestCases{
+		{
+			set:         NewStridedRanges[uint8](nil),
+			notContains: util.Seq(-1, utf8.MaxRune, 1),
+		},
+		{
+			set:         NewStridedRanges[rune](someRunes),
+			contains:    runes(someRunes...),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), runes(someRunes...)),
+		},
+		{
+			set:         StridedRanges[uint16]{{1, 3, 1}, {10, 20, 5}, {0x100, maxUint16, 0xfeff}},
+			contains:    runes(1, 2, 3, 10, 15, 20, 0x100, maxUint16),
+			notContains: util.Concat(runes(-1, 0, 4, 9, 11, 19, 21, 0x101), util.Seq(maxUint16+1, utf8.MaxRune, 1)),
+		},
+	}.run(t)
+}
+
+func TestNewStridedRanges(t *testing.T) {
+	t.Parallel()
+	got := NewStridedRanges[uint16](slices.Collect(util.Concat(
+		util.Seq('0', '9', 1),
+		util.Seq(0x100, 0x136, 2),
+	)))
+	util.Equal(t, "runes.StridedRanges[uint16]{{Lo: 0x30, Hi: 0x39, Stride: 0x1}, {Lo: 0x100, Hi: 0x136, Stride: 0x2}}",
+		got.GoString(), "unexpected ranges")
+	util.Equal(t, 0, len(NewStridedRanges[uint8](nil)), "unexpected length")
+}
+
 func TestInterval(t *testing.T) {
 	t.Parallel()
 	setTestCases{
@@ -158,6 +192,11 @@ func TestUniform(t *testing.T) {
 			contains:    util.Seq(maxUint8, maxUint8+maxUint8*maxUint8, maxUint8),
 			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), util.Seq(maxUint8, maxUint8+maxUint8*maxUint8, maxUint8)),
 		},
+		{
+			set:         Uniform[uint8]{10, 12, 2},
+			contains:    runes(10, 12),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), runes(10, 12)),
+		},
 		{
 			set:         Uniform[uint8]{3, 31, 7},
 			contains:    runes(3, 10, 17, 24, 31),
@@ -208,6 +247,328 @@ func TestBitmap(t *testing.T) {
 	}.run(t)
 }
 
+func TestBitmapMinMax(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		rs       []rune
+		min, max uint32
+	}{
+		{nil, MaxUint32, MaxUint32},
+		{[]rune{7}, 7, 7},
+		{[]rune{1, 3, 99, 410}, 1, 410},
+		{[]rune{1, 8}, 1, 8},
+		{[]rune{1, 9}, 1, 9},
+		{[]rune{0, utf8.MaxRune}, 0, utf8.MaxRune},
+	}
+
+	for i, tc := range testCases {
+		bm := NewBitmap(tc.rs)
+		util.Equal(t, tc.min, bm.Min(), "index=%v; Min", i)
+		util.Equal(t, tc.max, bm.Max(), "index=%v; Max", i)
+	}
+}
+
+func TestTwoLevel(t *testing.T) {
+	t.Parallel()
+	someRunes := []rune{1, 3, 63, 64, 410, 0x10000, utf8.MaxRune}
+	setTestCases{
+		{
+			set:         NewTwoLevel(nil),
+			notContains: util.Seq(-1, utf8.MaxRune, 1),
+		},
+		{
+			set:         NewTwoLevel(someRunes),
+			contains:    runes(someRunes...),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), runes(someRunes...)),
+		},
+		{
+			set:         NewTwoLevel(slices.Collect(util.Seq(0x100, 0x2ff, 1))),
+			contains:    util.Seq(0x100, 0x2ff, 1),
+			notContains: util.Concat(util.Seq(-1, 0xff, 1), util.Seq(0x300, utf8.MaxRune, 1)),
+		},
+	}.run(t)
+}
+
+func TestNewTwoLevel(t *testing.T) {
+	t.Parallel()
+	// full and empty blocks are shared
+	x := NewTwoLevel(slices.Collect(util.Concat(
+		util.Seq(0x40, 0xbf, 1),
+		util.Seq(0x140, 0x17f, 1),
+		util.Seq(0x201, 0x201, 1),
+	)))
+	util.Equal(t, "runes.TwoLevel{First: 0x1, Index: []uint16{0x0, 0x0, 0x1, 0x1, 0x0, 0x1, 0x1, 0x2}, Leaves: []uint64{0xffffffffffffffff, 0x0, 0x2}, Ranks: []uint32{0, 64, 128, 128, 128, 192, 192, 192, 193}}",
+		x.GoString(), "unexpected TwoLevel")
+	util.Equal(t, uint32(0x40), x.Min(), "Min")
+	util.Equal(t, uint32(0x201), x.Max(), "Max")
+
+	checkTablesEquivalent(t, func(rs []rune) MinMaxSet { return NewTwoLevel(rs) })
+}
+
+// checkTablesEquivalent checks that the sets returned by `build` for the runes
+// of each of util.Tables have exactly those runes.
+func checkTablesEquivalent(t *testing.T, build func([]rune) MinMaxSet) {
+	t.Helper()
+	for name, rt := range util.Tables {
+		s := build(slices.Collect(util.RangeTableIter(rt)))
+		util.Equal(t, true, Equal(FromRangeTable(rt), s), "table %s", name)
+	}
+}
+
+func TestSparseBitmap(t *testing.T) {
+	t.Parallel()
+	someRunes := []rune{1, 3, 63, 64, 410, 0x3000, 0x10000, utf8.MaxRune}
+	setTestCases{
+		{
+			set:         NewSparseBitmap(nil),
+			notContains: util.Seq(-1, utf8.MaxRune, 1),
+		},
+		{
+			set:         NewSparseBitmap(someRunes),
+			contains:    runes(someRunes...),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), runes(someRunes...)),
+		},
+		{
+			set:         NewSparseBitmap(slices.Collect(util.Seq(0x100, 0x2ff, 1))),
+			contains:    util.Seq(0x100, 0x2ff, 1),
+			notContains: util.Concat(util.Seq(-1, 0xff, 1), util.Seq(0x300, utf8.MaxRune, 1)),
+		},
+	}.run(t)
+}
+
+func TestNewSparseBitmap(t *testing.T) {
+	t.Parallel()
+	x := NewSparseBitmap([]rune{0x20, 0x3f, 0x40, 0x3000, 0x3001})
+	util.Equal(t, "runes.SparseBitmap{Blocks: []uint16{0x0, 0x1, 0xc0}, Words: []uint64{0x8000000100000000, 0x1, 0x3}, Ranks: []uint32{0, 2, 3, 5}}",
+		x.GoString(), "unexpected SparseBitmap")
+	util.Equal(t, uint32(0x20), x.Min(), "Min")
+	util.Equal(t, uint32(0x3001), x.Max(), "Max")
+
+	checkTablesEquivalent(t, func(rs []rune) MinMaxSet { return NewSparseBitmap(rs) })
+}
+
+func TestRoaring(t *testing.T) {
+	t.Parallel()
+	someRunes := []rune{1, 3, 63, 64, 410, 0x3000, 0x10000, utf8.MaxRune}
+	setTestCases{
+		{
+			set:         NewRoaring(nil),
+			notContains: util.Seq(-1, utf8.MaxRune, 1),
+		},
+		{
+			set:         NewRoaring(someRunes),
+			contains:    runes(someRunes...),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), runes(someRunes...)),
+		},
+		{
+			// runs
+			set:         NewRoaring(slices.Collect(util.Seq(0x100, 0x2fff, 1))),
+			contains:    util.Seq(0x100, 0x2fff, 1),
+			notContains: util.Concat(util.Seq(-1, 0xff, 1), util.Seq(0x3000, utf8.MaxRune, 1)),
+		},
+		{
+			// bitmap
+			set:      NewRoaring(slices.Collect(util.Seq(0x2000, 0x2fff, 3))),
+			contains: util.Seq(0x2000, 0x2fff, 3),
+			notContains: util.Concat(util.Seq(-1, 0x1fff, 1), util.Seq(0x2001, 0x2ffd, 3),
+				util.Seq(0x2002, 0x2ffe, 3), util.Seq(0x3000, utf8.MaxRune, 1)),
+		},
+	}.run(t)
+}
+
+func TestNewRoaring(t *testing.T) {
+	t.Parallel()
+	x := NewRoaring(slices.Collect(util.Concat(
+		runes(1, 3, 99),
+		util.Seq(0x1000, 0x10ff, 1),
+		util.Seq(0x2000, 0x2fff, 3),
+	)))
+	kinds := make([]RoaringKind, len(x.Containers))
+	for i, c := range x.Containers {
+		kinds[i] = c.Kind
+	}
+	util.Equal(t, "[0 1 2]", fmt.Sprint(x.Keys), "unexpected keys")
+	util.Equal(t, fmt.Sprint([]RoaringKind{RoaringArray, RoaringRuns, RoaringBitmap}), fmt.Sprint(kinds),
+		"unexpected container kinds")
+	util.Equal(t, uint32(1), x.Min(), "Min")
+	util.Equal(t, uint32(0x2fff), x.Max(), "Max")
+
+	checkTablesEquivalent(t, func(rs []rune) MinMaxSet { return NewRoaring(rs) })
+}
+
+// mixedUnion has a segment of each kind. Its runes are mixedUnionRunes.
+var mixedUnion = MixedUnion{
+	Kinds:  []MixedKind{MixedInterval, MixedStrided, MixedSlice, MixedBitmap},
+	Bounds: []uint32{'a', 'z', 0x100, 0x106, 0x1000, 0x1999, 0x2001, 0x2081},
+	Args:   []uint32{0, 2, 0, 0},
+	Runes:  []uint32{0x1000, 0x1234, 0x1999},
+	Words:  []uint64{0b1011, 0, 1},
+}
+
+var mixedUnionRunes = slices.Collect(util.Concat(
+	util.Seq('a', 'z', 1),
+	util.Seq(0x100, 0x106, 2),
+	runes(0x1000, 0x1234, 0x1999, 0x2001, 0x2002, 0x2004, 0x2081),
+))
+
+func TestMixedUnion(t *testing.T) {
+	t.Parallel()
+	setTestCases{
+		{
+			set:         MixedUnion{},
+			notContains: util.Seq(-1, utf8.MaxRune, 1),
+		},
+		{
+			set:         mixedUnion,
+			contains:    runes(mixedUnionRunes...),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), runes(mixedUnionRunes...)),
+		},
+		{
+			set:         NewMixedUnion(mixedUnionRunes),
+			contains:    runes(mixedUnionRunes...),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1), runes(mixedUnionRunes...)),
+		},
+	}.run(t)
+}
+
+func TestNewMixedUnion(t *testing.T) {
+	t.Parallel()
+	x := NewMixedUnion([]rune{-1, 5, utf8.MaxRune + 1, 3, 5})
+	util.Equal(t, "{[1] [3 5] [2]}", fmt.Sprint(struct{ k, b, a any }{x.Kinds, x.Bounds, x.Args}),
+		"unexpected segments")
+
+	l := NewMixedUnion(slices.Collect(util.RangeTableIter(util.Tables["L"])))
+	for _, kind := range []MixedKind{MixedInterval, MixedStrided, MixedBitmap} {
+		util.Equal(t, true, slices.Contains(l.Kinds, kind), "L has no segment of kind %d", kind)
+	}
+
+	for _, stepCost := range []int{0, defaultStepCost, 1000} {
+		checkTablesEquivalent(t, func(rs []rune) MinMaxSet {
+			s := NewMixedUnion(rs, StepCost(stepCost))
+			util.Equal(t, nil, validateMixedUnion(s), "StepCost=%d", stepCost)
+			return s
+		})
+	}
+}
+
+func TestMixedUnionMalformed(t *testing.T) {
+	t.Parallel()
+	// none of these must panic
+	for _, x := range []MixedUnion{
+		{Kinds: []MixedKind{MixedStrided}, Bounds: []uint32{1, 5}, Args: []uint32{0}},
+		{Kinds: []MixedKind{MixedInterval, MixedStrided}, Bounds: []uint32{1, 5, 7, 9}},
+		{Kinds: []MixedKind{MixedInterval}, Bounds: []uint32{1, 5, 7, 9}},
+		{Kinds: []MixedKind{MixedInterval, MixedSlice}, Bounds: []uint32{1, 5, 7}},
+		{Kinds: []MixedKind{MixedSlice}, Bounds: []uint32{1, 300}, Args: []uint32{2}, Runes: []uint32{1, 2}},
+		{Kinds: []MixedKind{MixedSlice}, Bounds: []uint32{5, 1}, Args: []uint32{0}, Runes: []uint32{1, 5}},
+		{Kinds: []MixedKind{MixedBitmap}, Bounds: []uint32{1, 300}, Args: []uint32{1}, Words: []uint64{7, 7}},
+		{Kinds: []MixedKind{MixedBitmap}, Bounds: []uint32{1, 300}, Args: []uint32{MaxUint32}},
+		{Kinds: []MixedKind{MixedKind(9)}, Bounds: []uint32{1, 5}, Args: []uint32{0}},
+	} {
+		for r := rune(-1); r <= 0x200; r++ {
+			x.Contains(r)
+			x.Rank(r)
+			x.Next(r)
+			x.Prev(r)
+		}
+		for i := range x.Len() + 1 {
+			x.Select(i)
+		}
+		for range x.Ranges() {
+		}
+		_, err := x.MarshalBinary()
+		util.Equal(t, nil, err, "MarshalBinary")
+	}
+}
+
+func TestUnionEmptyMembers(t *testing.T) {
+	t.Parallel()
+	setTestCases{
+		{
+			set:         Union[MinMaxSet]{},
+			notContains: util.Seq(-1, utf8.MaxRune, 1),
+		},
+		{
+			set:         Union[MinMaxSet]{NewBitmap(nil), LinearSlice[uint8]{}},
+			notContains: util.Seq(-1, utf8.MaxRune, 1),
+		},
+		{
+			set: Union[MinMaxSet]{
+				NewBitmap(nil),
+				Interval[uint8]{'a', 'z'},
+				LinearSlice[uint16]{'m', 0x100, 0x102},
+				LinearSlice[uint8]{},
+			},
+			contains: util.Concat(util.Seq('a', 'z', 1), runes(0x100, 0x102)),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1),
+				util.Concat(util.Seq('a', 'z', 1), runes(0x100, 0x102))),
+		},
+	}.run(t)
+}
+
+func TestSortedUnion(t *testing.T) {
+	t.Parallel()
+	setTestCases{
+		{
+			set:         MustSortedUnion[MinMaxSet](),
+			notContains: util.Seq(-1, utf8.MaxRune, 1),
+		},
+		{
+			set: MustSortedUnion[MinMaxSet](
+				Interval[uint8]{'a', 'z'},
+				LinearSlice[uint16]{0x100, 0x102},
+				NewBitmap(nil),
+				Uniform[rune]{0x10000, 0x10010, 4},
+			),
+			contains: util.Concat(util.Seq('a', 'z', 1), runes(0x100, 0x102), util.Seq(0x10000, 0x10010, 4)),
+			notContains: util.Except(util.Seq(-1, utf8.MaxRune, 1),
+				util.Concat(util.Seq('a', 'z', 1), runes(0x100, 0x102), util.Seq(0x10000, 0x10010, 4))),
+		},
+	}.run(t)
+}
+
+func TestNewSortedUnion(t *testing.T) {
+	t.Parallel()
+	x, err := NewSortedUnion[MinMaxSet](Interval[uint8]{1, 3}, Interval[uint8]{5, 4}, LinearSlice[uint8]{7, 9})
+	util.MustEqual(t, nil, err, "empty members are skipped")
+	util.Equal(t, 2, len(x.Members()), "Members")
+	util.Equal(t, uint32(1), x.Min(), "Min")
+	util.Equal(t, uint32(9), x.Max(), "Max")
+
+	_, err = NewSortedUnion(Interval[uint8]{1, 3}, Interval[uint8]{3, 5})
+	util.Equal(t, true, errors.Is(err, ErrUnsortedUnion), "overlapping: %v", err)
+
+	_, err = NewSortedUnion(Interval[uint8]{5, 6}, Interval[uint8]{1, 3})
+	util.Equal(t, true, errors.Is(err, ErrUnsortedUnion), "unsorted: %v", err)
+
+	for name, rt := range util.Tables {
+		s := FromRangeTable(rt)
+		u, err := NewSortedUnion(s)
+		util.MustEqual(t, nil, err, "table %s", name)
+		util.Equal(t, true, Equal(s, u), "table %s", name)
+	}
+}
+
+func TestBitmapHeaderMaxPosition(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		rs       []rune
+		expected byte
+	}{
+		{[]rune{1}, 0},
+		{[]rune{1, 3}, 2},
+		{[]rune{0, 7}, 7},
+		{[]rune{1, 9}, 0},
+		{[]rune{utf8.MaxRune - 5, utf8.MaxRune}, 5},
+	}
+
+	for i, tc := range testCases {
+		bm := NewBitmap(tc.rs)
+		util.Equal(t, tc.expected, bm[2]>>bmPosShift, "index=%v; position", i)
+		util.Equal(t, uint32(tc.rs[0]), bm.Min(), "index=%v; Min", i)
+	}
+}
+
 func TestCeilDiv(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
@@ -12,6 +12,10 @@ func (x Union[T]) Sizeof() uintptr {
 	return util.SizeofSlice(x)
 }
 
+func (x SortedUnion[T]) Sizeof() uintptr {
+	return util.SizeofSlice(x.elems) + util.SizeofSlice(x.bounds) + util.SizeofSlice(x.ranks)
+}
+
 func (x LinearSlice[T]) Sizeof() uintptr {
 	return util.SizeofSlice(x)
 }
@@ -20,6 +24,14 @@ func (x BinarySlice[T]) Sizeof() uintptr {
 	return util.SizeofSlice(x)
 }
 
+func (x RangeSlice[T]) Sizeof() uintptr {
+	return util.SizeofSlice(x)
+}
+
+func (x StridedRanges[T]) Sizeof() uintptr {
+	return util.SizeofSlice(x)
+}
+
 func (x Interval[T]) Sizeof() uintptr {
 	return unsafe.Sizeof(x)
 }
@@ -31,3 +43,42 @@ func (x Uniform[T]) Sizeof() uintptr {
 func (x Bitmap) Sizeof() uintptr {
 	return unsafe.Sizeof(x) + uintptr(len(x))
 }
+
+func (x TwoLevel) Sizeof() uintptr {
+	return unsafe.Sizeof(x.First) + util.SizeofSlice(x.Index) + util.SizeofSlice(x.Leaves) +
+		util.SizeofSlice(x.Ranks)
+}
+
+func (x SparseBitmap) Sizeof() uintptr {
+	return util.SizeofSlice(x.Blocks) + util.SizeofSlice(x.Words) + util.SizeofSlice(x.Ranks)
+}
+
+func (x Roaring) Sizeof() uintptr {
+	return util.SizeofSlice(x.Keys) + util.SizeofSlice(x.Containers) + util.SizeofSlice(x.Ranks)
+}
+
+func (c RoaringContainer) Sizeof() uintptr {
+	return unsafe.Sizeof(c.Kind) + util.SizeofSlice(c.Data)
+}
+
+func (x MixedUnion) Sizeof() uintptr {
+	return util.SizeofSlice(x.Kinds) + util.SizeofSlice(x.Bounds) + util.SizeofSlice(x.Args) +
+		util.SizeofSlice(x.Runes) + util.SizeofSlice(x.Words) + util.SizeofSlice(x.Ranks)
+}
+
+func (x AndSet[A, B]) Sizeof() uintptr {
+	return unsafe.Sizeof(x.min) + unsafe.Sizeof(x.max) + sizeof(x.a) + sizeof(x.b)
+}
+
+func (x OrSet[A, B]) Sizeof() uintptr {
+	return unsafe.Sizeof(x.min) + unsafe.Sizeof(x.max) + sizeof(x.a) + sizeof(x.b)
+}
+
+func (x NotSet[S]) Sizeof() uintptr {
+	return unsafe.Sizeof(x.min) + unsafe.Sizeof(x.max) + sizeof(x.s)
+}
+
+func sizeof(x Set) uintptr {
+	s, _ := util.Sizeof(x)
+	return s
+}
@@ -0,0 +1,251 @@
+// Package ucd parses the files of the Unicode Character Database into sets of
+// package runes, which allows building tables for versions of Unicode other
+// than the one of package unicode, and for properties it does not provide.
+//
+// Most files of the database, like Scripts.txt, PropList.txt,
+// DerivedCoreProperties.txt or emoji-data.txt, have lines with a rune or a
+// range of runes and a property, followed by an optional comment:
+//
+//	0041..005A    ; Latin # L&  [26] LATIN CAPITAL LETTER A..LATIN CAPITAL LETTER Z
+//	00AA          ; Latin # Lo       FEMININE ORDINAL INDICATOR
+//
+// Those are read with [Parse]. UnicodeData.txt has its own format, and is read
+// with [ParseUnicodeData].
+package ucd
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/diegommm/runes"
+)
+
+// ErrSyntax is wrapped by the errors returned for malformed files.
+var ErrSyntax = errors.New("ucd: syntax error")
+
+// Parse reads a file in the format of Scripts.txt and most other files of the
+// database, and returns a set for each property value found, built with
+// [runes.FromRangeTable] and the given options.
+//
+// The name of each set is the second field of the line, like "Latin" or
+// "White_Space". Files with more fields, like DerivedNormalizationProps.txt,
+// name their sets joining the fields with "=", like "NFD_QC=N".
+func Parse(r io.Reader, opts ...runes.Option) (map[string]runes.MinMaxSet, error) {
+	props := map[string][]unicode.Range32{}
+	err := scanLines(r, func(fields []string) error {
+		if len(fields) < 2 {
+			return errors.New("expected at least two fields")
+		}
+		lo, hi, err := parseRange(fields[0])
+		if err != nil {
+			return err
+		}
+		name := strings.Join(fields[1:], "=")
+		if name == "" {
+			return errors.New("empty property")
+		}
+		props[name] = appendRange(props[name], lo, hi)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return compileAll(props, opts), nil
+}
+
+// ParseUnicodeData reads a file in the format of UnicodeData.txt, and returns
+// a set for each General Category found, built with [runes.FromRangeTable] and
+// the given options. The sets are named with the short names of the categories,
+// like "Lu", and sets for the major classes, like "L", are also included.
+//
+// Unassigned runes are not listed in the file, so neither "Cn" nor "C" include
+// them, consistently with the tables of package unicode.
+func ParseUnicodeData(r io.Reader, opts ...runes.Option) (map[string]runes.MinMaxSet, error) {
+	cats := map[string][]unicode.Range32{}
+	first := rune(-1) // first rune of a range, if pending
+	err := scanLines(r, func(fields []string) error {
+		if len(fields) < 3 {
+			return errors.New("expected at least three fields")
+		}
+		r, err := parseRune(fields[0])
+		if err != nil {
+			return err
+		}
+		gc := fields[2]
+		if len(gc) != 2 {
+			return fmt.Errorf("invalid General Category %q", gc)
+		}
+
+		// large ranges are listed as two lines with names like
+		// "<CJK Ideograph, First>" and "<CJK Ideograph, Last>"
+		lo := r
+		switch name := fields[1]; {
+		case strings.HasSuffix(name, ", First>"):
+			if first >= 0 {
+				return errors.New("unterminated range")
+			}
+			first = r
+			return nil
+		case strings.HasSuffix(name, ", Last>"):
+			if first < 0 || first > r {
+				return errors.New("range end without start")
+			}
+			lo, first = first, -1
+		case first >= 0:
+			return errors.New("unterminated range")
+		}
+		cats[gc] = appendRange(cats[gc], lo, r)
+		cats[gc[:1]] = appendRange(cats[gc[:1]], lo, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if first >= 0 {
+		return nil, fmt.Errorf("%w: unterminated range at end of file", ErrSyntax)
+	}
+	return compileAll(cats, opts), nil
+}
+
+// ParseFile is like [Parse], but reads the file at the given path.
+func ParseFile(path string, opts ...runes.Option) (map[string]runes.MinMaxSet, error) {
+	return parseFile(path, Parse, opts)
+}
+
+// ParseUnicodeDataFile is like [ParseUnicodeData], but reads the file at the
+// given path.
+func ParseUnicodeDataFile(path string, opts ...runes.Option) (map[string]runes.MinMaxSet, error) {
+	return parseFile(path, ParseUnicodeData, opts)
+}
+
+func parseFile(path string, parse func(io.Reader, ...runes.Option) (map[string]runes.MinMaxSet, error), opts []runes.Option) (map[string]runes.MinMaxSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sets, err := parse(f, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sets, nil
+}
+
+// scanLines calls `f` with the trimmed fields of each line of `r` that is not
+// empty after removing comments.
+func scanLines(r io.Reader, f func(fields []string) error) error {
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		s, _, _ := strings.Cut(sc.Text(), "#")
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		fields := strings.Split(s, ";")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if err := f(fields); err != nil {
+			return fmt.Errorf("%w: line %d: %w", ErrSyntax, line, err)
+		}
+	}
+	return sc.Err()
+}
+
+// parseRange parses a rune like "0041" or a range like "0041..005A".
+func parseRange(s string) (lo, hi rune, err error) {
+	los, his, isRange := strings.Cut(s, "..")
+	if lo, err = parseRune(los); err != nil || !isRange {
+		return lo, lo, err
+	}
+	if hi, err = parseRune(his); err != nil {
+		return 0, 0, err
+	}
+	if lo > hi {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	return lo, hi, nil
+}
+
+// parseRune parses a rune in hexadecimal, like "0041".
+func parseRune(s string) (rune, error) {
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || v > utf8.MaxRune {
+		return 0, fmt.Errorf("invalid rune %q", s)
+	}
+	return rune(v), nil
+}
+
+// appendRange appends the range lo..hi to `rs`, extending the last range
+// instead if they overlap or are adjacent, as consecutive lines usually are.
+func appendRange(rs []unicode.Range32, lo, hi rune) []unicode.Range32 {
+	if n := len(rs); n > 0 && rs[n-1].Lo <= uint32(lo) && uint32(lo) <= rs[n-1].Hi+1 {
+		rs[n-1].Hi = max(rs[n-1].Hi, uint32(hi))
+		return rs
+	}
+	return append(rs, unicode.Range32{Lo: uint32(lo), Hi: uint32(hi), Stride: 1})
+}
+
+func compileAll(m map[string][]unicode.Range32, opts []runes.Option) map[string]runes.MinMaxSet {
+	res := make(map[string]runes.MinMaxSet, len(m))
+	for name, rs := range m {
+		res[name] = runes.FromRangeTable(rangeTable(rs), opts...)
+	}
+	return res
+}
+
+// rangeTable returns a valid table with the runes of the given ranges, which
+// have a stride of 1 and may be unsorted or overlap. Runs of equally spaced
+// single runes are joined in a range with that stride.
+func rangeTable(rs []unicode.Range32) *unicode.RangeTable {
+	slices.SortFunc(rs, func(a, b unicode.Range32) int { return cmp.Compare(a.Lo, b.Lo) })
+	var merged []unicode.Range32
+	for _, r := range rs {
+		merged = appendRange(merged, rune(r.Lo), rune(r.Hi))
+	}
+
+	rt := new(unicode.RangeTable)
+	for i := 0; i < len(merged); {
+		r, j := merged[i], i+1
+		if r.Lo == r.Hi && j < len(merged) && merged[j].Lo == merged[j].Hi {
+			r.Stride = merged[j].Lo - r.Lo
+			for ; j < len(merged) && merged[j].Lo == merged[j].Hi && merged[j].Lo-merged[j-1].Lo == r.Stride; j++ {
+			}
+			r.Hi = merged[j-1].Hi
+		}
+		i = j
+
+		// split the range at 0x10000 between R16 and R32
+		if r.Lo <= 0xFFFF {
+			hi := min(r.Hi, r.Lo+(0xFFFF-r.Lo)/r.Stride*r.Stride)
+			rt.R16 = append(rt.R16, unicode.Range16{Lo: uint16(r.Lo), Hi: uint16(hi), Stride: uint16(singleStride(r.Lo, hi, r.Stride))})
+			if hi <= unicode.MaxLatin1 {
+				rt.LatinOffset++
+			}
+			if hi == r.Hi {
+				continue
+			}
+			r.Lo = hi + r.Stride
+			r.Stride = singleStride(r.Lo, r.Hi, r.Stride)
+		}
+		rt.R32 = append(rt.R32, r)
+	}
+	return rt
+}
+
+// singleStride returns the stride of a range, which is 1 if it has a single
+// rune.
+func singleStride(lo, hi, stride uint32) uint32 {
+	if lo == hi {
+		return 1
+	}
+	return stride
+}
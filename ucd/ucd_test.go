@@ -0,0 +1,155 @@
+package ucd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/diegommm/runes"
+	"github.com/diegommm/runes/util"
+)
+
+func TestParseFile(t *testing.T) {
+	t.Parallel()
+	sets, err := ParseFile("testdata/PropList.txt")
+	util.MustEqual(t, nil, err, "ParseFile")
+	util.Equal(t, "ASCII_Hex_Digit NFD_QC=N White_Space",
+		strings.Join(slices.Sorted(maps.Keys(sets)), " "), "names")
+	testRanges(t, sets["White_Space"], [][2]rune{{9, 13}, {0x20, 0x20}, {0x85, 0x85}})
+	testRanges(t, sets["ASCII_Hex_Digit"], [][2]rune{{'0', '9'}, {'A', 'F'}, {'a', 'f'}})
+	testRanges(t, sets["NFD_QC=N"], [][2]rune{{0x340, 0x341}})
+}
+
+func TestParseUnicodeDataFile(t *testing.T) {
+	t.Parallel()
+	sets, err := ParseUnicodeDataFile("testdata/UnicodeData.txt")
+	util.MustEqual(t, nil, err, "ParseUnicodeDataFile")
+	util.Equal(t, "L Ll Lo Lu S So",
+		strings.Join(slices.Sorted(maps.Keys(sets)), " "), "names")
+	testRanges(t, sets["Lu"], [][2]rune{{'A', 'B'}})
+	testRanges(t, sets["Lo"], [][2]rune{{0xaa, 0xaa}, {0x3400, 0x4dbf}})
+	testRanges(t, sets["L"], [][2]rune{{'A', 'B'}, {'a', 'a'}, {0xaa, 0xaa}, {0x3400, 0x4dbf}})
+	testRanges(t, sets["So"], [][2]rune{{0x4dc0, 0x4dc0}})
+}
+
+// TestParseScripts parses a file generated from the tables of package unicode,
+// and compares the sets with the tables.
+func TestParseScripts(t *testing.T) {
+	t.Parallel()
+	var sb strings.Builder
+	for name, rt := range unicode.Scripts {
+		for lo, hi := range tableRanges(rt) {
+			if lo == hi {
+				fmt.Fprintf(&sb, "%04X          ; %s # comment\n", lo, name)
+			} else {
+				fmt.Fprintf(&sb, "%04X..%04X    ; %s\n", lo, hi, name)
+			}
+		}
+	}
+	sets, err := Parse(strings.NewReader(sb.String()))
+	util.MustEqual(t, nil, err, "Parse")
+	util.MustEqual(t, len(unicode.Scripts), len(sets), "number of sets")
+	for name, rt := range unicode.Scripts {
+		util.Equal(t, true, runes.Equal(runes.FromRangeTable(rt), sets[name]),
+			"script %s", name)
+	}
+}
+
+// TestParseCategories parses a file in the format of UnicodeData.txt generated
+// from the tables of package unicode, and compares the sets with the tables.
+func TestParseCategories(t *testing.T) {
+	t.Parallel()
+	var sb strings.Builder
+	for name, rt := range unicode.Categories {
+		if len(name) != 2 {
+			continue
+		}
+		for lo, hi := range tableRanges(rt) {
+			if lo == hi {
+				fmt.Fprintf(&sb, "%04X;NAME;%s;0;L;;;;;N;;;;;\n", lo, name)
+				continue
+			}
+			fmt.Fprintf(&sb, "%04X;<Range, First>;%s;0;L;;;;;N;;;;;\n", lo, name)
+			fmt.Fprintf(&sb, "%04X;<Range, Last>;%s;0;L;;;;;N;;;;;\n", hi, name)
+		}
+	}
+	sets, err := ParseUnicodeData(strings.NewReader(sb.String()))
+	util.MustEqual(t, nil, err, "ParseUnicodeData")
+	for name, rt := range unicode.Categories {
+		util.Equal(t, true, runes.Equal(runes.FromRangeTable(rt), sets[name]),
+			"category %s", name)
+	}
+}
+
+func TestRangeTable(t *testing.T) {
+	t.Parallel()
+	rt := rangeTable([]unicode.Range32{
+		{Lo: 0x10002, Hi: 0x10002, Stride: 1},
+		{Lo: 'a', Hi: 'c', Stride: 1},
+		{Lo: 0xFFFE, Hi: 0xFFFE, Stride: 1},
+		{Lo: 0xFFFC, Hi: 0xFFFC, Stride: 1},
+		{Lo: 'b', Hi: 'e', Stride: 1},
+		{Lo: 'f', Hi: 'f', Stride: 1},
+		{Lo: 0x10000, Hi: 0x10000, Stride: 1},
+		{Lo: 0x20000, Hi: 0x2A6DF, Stride: 1},
+	})
+	util.Equal(t, "[{97 102 1} {65532 65534 2}]", fmt.Sprint(rt.R16), "R16")
+	util.Equal(t, "[{65536 65538 2} {131072 173791 1}]", fmt.Sprint(rt.R32), "R32")
+	util.Equal(t, 1, rt.LatinOffset, "LatinOffset")
+
+	sets, err := Parse(strings.NewReader("0000..10FFFF ; Any\n"))
+	util.MustEqual(t, nil, err, "Parse")
+	testRanges(t, sets["Any"], [][2]rune{{0, 0x10FFFF}})
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+	testCases := []string{
+		"0041\n",
+		"0041 ; \n",
+		"XYZ ; Prop\n",
+		"0042..0041 ; Prop\n",
+		"0041..XYZ ; Prop\n",
+		"110000 ; Prop\n",
+	}
+	for i, tc := range testCases {
+		_, err := Parse(strings.NewReader(tc))
+		util.Equal(t, true, errors.Is(err, ErrSyntax), "index=%v; unexpected error: %v", i, err)
+	}
+
+	udTestCases := []string{
+		"0041;A\n",
+		"0041;A;Lux\n",
+		"XYZ;A;Lu\n",
+		"3400;<X, First>;Lo\n",
+		"3400;<X, First>;Lo\n3401;A;Lo\n",
+		"3400;<X, First>;Lo\n3401;<Y, First>;Lo\n",
+		"4DBF;<X, Last>;Lo\n",
+		"4DBF;<X, First>;Lo\n3400;<X, Last>;Lo\n",
+	}
+	for i, tc := range udTestCases {
+		_, err := ParseUnicodeData(strings.NewReader(tc))
+		util.Equal(t, true, errors.Is(err, ErrSyntax), "index=%v; unexpected error: %v", i, err)
+	}
+
+	_, err := ParseFile("testdata/nope.txt")
+	util.Equal(t, true, err != nil, "expected error")
+}
+
+func testRanges(t *testing.T, s runes.MinMaxSet, expected [][2]rune) {
+	t.Helper()
+	var got [][2]rune
+	for lo, hi := range s.(runes.Enumerable).Ranges() {
+		got = append(got, [2]rune{lo, hi})
+	}
+	util.Equal(t, fmt.Sprint(expected), fmt.Sprint(got), "unexpected ranges")
+}
+
+// tableRanges returns the ranges of consecutive runes of `rt`.
+func tableRanges(rt *unicode.RangeTable) func(func(lo, hi rune) bool) {
+	return runes.FromRangeTable(rt).(runes.Enumerable).Ranges()
+}
@@ -26,7 +26,7 @@ func SizeofSlice[S ~[]E, E any](s S) uintptr {
 	switch reflect.TypeOf(new(E)).Elem().Kind() {
 	case reflect.Array, reflect.Chan, reflect.Func, reflect.Interface,
 		reflect.Map, reflect.Pointer, reflect.Slice:
-		size := uintptr(len(s)) * unsafe.Sizeof(*new(E))
+		size += uintptr(len(s)) * unsafe.Sizeof(*new(E))
 		for _, elem := range s {
 			so, ok := any(elem).(Sizerof)
 			if ok {
@@ -5,6 +5,7 @@ package util
 import (
 	"testing"
 	"unicode"
+	"unsafe"
 )
 
 func TestSizeof(t *testing.T) {
@@ -22,6 +23,10 @@ func TestSizeofSlice(t *testing.T) {
 	}
 	s = SizeofSlice[[]set, set]([]set{ContainsFunc(nil)})
 	MustEqual(t, true, s != 0, "expected non-zero size")
+
+	// the elements of slices of interfaces are counted with the header
+	s = SizeofSlice([]set{ContainsFunc(nil), ContainsFunc(nil)})
+	MustEqual(t, unsafe.Sizeof([]set{})+2*unsafe.Sizeof(set(nil)), s, "unexpected size")
 }
 
 func TestSizeofUnicodeRangeTable(t *testing.T) {
@@ -1,78 +1,9 @@
 package util
 
-import (
-	"bufio"
-	"io"
-	"os"
-	"strconv"
-	"strings"
-	"testing"
-	"unicode"
-)
+import "unicode"
 
-const (
-	writeTableFilesEnvVar = "WRITE_TABLE_FILES"
-	tableFilesDir         = "range_tables.out" // ends in ".out" to git-ignore it
-)
-
-func TestWriteTableFiles(t *testing.T) {
-	v, err := strconv.ParseBool(os.Getenv(writeTableFilesEnvVar))
-	if err != nil {
-		t.Skipf("NOTE: if you want to dump \"unicode\"'s package tables to "+
-			"one file per table in this directory, with one decimal rune per "+
-			"line, ordered asc, then set the environment variable %q to a "+
-			"truthy value (i.e. something strconv.ParseBool deems to be true)",
-			writeTableFilesEnvVar)
-	}
-	if !v {
-		t.Skip()
-	}
-	err = os.Mkdir(tableFilesDir, 0o755)
-	if err != nil && !strings.Contains(err.Error(), "file exists") {
-		t.Fatalf("create directory %q for tables: %v", tableFilesDir, err)
-	}
-
-	for name, rt := range tablesToWrite {
-		writeTableFile(t, name, rt)
-	}
-}
-
-func writeTableFile(t *testing.T, name string, rt *unicode.RangeTable) {
-	const mode = os.O_CREATE | os.O_TRUNC | os.O_WRONLY
-	filename := tableFilesDir + "/" + strings.ToLower(name)
-	f, err := os.OpenFile(filename, mode, 0o644)
-	MustEqual(t, true, err == nil, "open file %q for write: %v", filename, err)
-	defer func() {
-		err := f.Close()
-		MustEqual(t, true, err == nil, "close file %q: %v", filename, err)
-	}()
-	w := newErrRuneWriter(f)
-	for r := range RangeTableIter(rt) {
-		w.WriteString(strconv.Itoa(int(r)) + "\n")
-	}
-	MustEqual(t, true, w.err == nil, "write to file %q: %v", filename, err)
-	err = w.Flush()
-	MustEqual(t, true, err == nil, "flush changes to file %q: %v", filename, err)
-}
-
-func newErrRuneWriter(w io.Writer) *errRuneWriter {
-	return &errRuneWriter{Writer: bufio.NewWriter(w)}
-}
-
-type errRuneWriter struct {
-	*bufio.Writer
-	err error
-}
-
-func (w *errRuneWriter) WriteString(s string) (size int) {
-	if w.err != nil {
-		return 0
-	}
-	size, w.err = w.Writer.WriteString(s)
-	return
-}
-
-var tablesToWrite = map[string]*unicode.RangeTable{
+// Tables is a catalogue of the tables of package unicode, by name.
+var Tables = map[string]*unicode.RangeTable{
 	"Cc":     unicode.Cc,
 	"Cf":     unicode.Cf,
 	"Co":     unicode.Co,
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestTables(t *testing.T) {
+	for name, rt := range Tables {
+		MustEqual(t, true, rt != nil, "nil table %q", name)
+		for _, m := range []map[string]*unicode.RangeTable{
+			unicode.Categories,
+			unicode.Scripts,
+			unicode.Properties,
+		} {
+			if other, ok := m[name]; ok {
+				Equal(t, other, rt, "table %q differs from package unicode", name)
+			}
+		}
+	}
+}